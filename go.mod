@@ -6,10 +6,14 @@ require (
 	github.com/BurntSushi/toml v1.3.2
 	github.com/cenkalti/backoff v2.2.1+incompatible
 	github.com/json-iterator/go v1.1.12
+	github.com/miekg/dns v1.1.55
+	github.com/projectdiscovery/cdncheck v1.0.9
 	github.com/projectdiscovery/dnsx v1.1.5
+	github.com/projectdiscovery/retryabledns v1.0.35
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.7.0
 	github.com/stretchr/testify v1.8.4
+	github.com/weppos/publicsuffix-go v0.30.0
 )
 
 require (
@@ -19,18 +23,14 @@ require (
 	github.com/gorilla/css v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/microcosm-cc/bluemonday v1.0.25 // indirect
-	github.com/miekg/dns v1.1.55 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/projectdiscovery/blackrock v0.0.1 // indirect
-	github.com/projectdiscovery/cdncheck v1.0.9 // indirect
-	github.com/projectdiscovery/retryabledns v1.0.35 // indirect
 	github.com/projectdiscovery/utils v0.0.55 // indirect
 	github.com/saintfish/chardet v0.0.0-20230101081208-5e3ef4b5456d // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	github.com/weppos/publicsuffix-go v0.30.0 // indirect
 	github.com/yl2chen/cidranger v1.0.2 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/exp v0.0.0-20230420155640-133eef4313cb // indirect
@@ -1,7 +1,21 @@
 package resolver
 
 import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
 	"github.com/projectdiscovery/dnsx/libs/dnsx"
+	"github.com/projectdiscovery/retryabledns"
+)
+
+var (
+	// errNoAddresses is returned for names without A or AAAA records.
+	errNoAddresses = errors.New("no ips found")
+	// errNoAnswer is returned when no DNS server answered a query.
+	errNoAnswer = errors.New("no answer")
 )
 
 // DefaultResolvers contains the default known-to-be-good resolvers.
@@ -19,26 +33,60 @@ var DefaultResolvers = []string{
 	"208.67.220.220:53", // OpenDNS Secondary
 }
 
+// DefaultRetries is the default number of attempts for each lookup.
+const DefaultRetries = 1
+
+// Options defines the resolver configuration.
+type Options struct {
+	// Resolvers are the DNS servers to query in any form accepted by
+	// ParseUpstream, DefaultResolvers if empty.
+	Resolvers []string
+	// Retries is the number of attempts for each lookup, DefaultRetries if zero.
+	Retries int
+}
+
 // Resolver is a struct for resolving DNS names
 type Resolver struct {
-	client    *dnsx.DNSX
+	client *dnsx.DNSX
+	// client6 asks the AAAA questions, separately from the A ones of client.
+	client6   *dnsx.DNSX
 	resolvers []string
 }
 
-// New creates a new resolver struct with the default resolvers
-func New() (*Resolver, error) {
+// New creates a new resolver struct
+func New(opts Options) (*Resolver, error) {
 	var (
 		r   *Resolver
 		err error
 	)
 
+	if len(opts.Resolvers) == 0 {
+		opts.Resolvers = DefaultResolvers
+	}
+	if opts.Retries <= 0 {
+		opts.Retries = DefaultRetries
+	}
+
 	r = &Resolver{
-		client:    nil,
-		resolvers: []string{},
+		client: nil,
+	}
+	if r.resolvers, err = ParseUpstreams(opts.Resolvers); err != nil {
+		return nil, err
 	}
 
-	// TODO: allow client to pass in MaxRetries
-	r.client, err = dnsx.New(dnsx.Options{BaseResolvers: DefaultResolvers, MaxRetries: 1})
+	r.client, err = dnsx.New(dnsx.Options{
+		BaseResolvers: r.resolvers,
+		MaxRetries:    opts.Retries,
+		QuestionTypes: []uint16{dns.TypeA},
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.client6, err = dnsx.New(dnsx.Options{
+		BaseResolvers: r.resolvers,
+		MaxRetries:    opts.Retries,
+		QuestionTypes: []uint16{dns.TypeAAAA},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +94,125 @@ func New() (*Resolver, error) {
 	return r, nil
 }
 
-// WithResolvers sets the resolvers to use for the resolver
-func (r *Resolver) WithResolvers(resolvers []string) *Resolver {
-	r.resolvers = resolvers
-	return r
+// Resolvers returns the DNS servers queried by the resolver.
+func (r *Resolver) Resolvers() []string {
+	return r.resolvers
+}
+
+// Lookup resolves host to its IPv4 addresses.
+func (r *Resolver) Lookup(host string) ([]string, error) {
+	return r.client.Lookup(host)
+}
+
+// Query resolves the A and AAAA records of host, following CNAMEs. Each
+// question is asked on its own so that a failed AAAA question doesn't hide
+// the A answer, and an error is only returned if no DNS server answered
+// either question.
+func (r *Resolver) Query(host string) (*Answer, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		answer := &Answer{}
+		if ip.To4() != nil {
+			answer.IPs = []string{host}
+		} else {
+			answer.IPv6 = []string{host}
+		}
+		return answer, nil
+	}
+
+	var (
+		clients = []*dnsx.DNSX{r.client, r.client6}
+		results = make([]*retryabledns.DNSData, len(clients))
+		errs    = make([]error, len(clients))
+		wg      sync.WaitGroup
+	)
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, client *dnsx.DNSX) {
+			defer wg.Done()
+			results[i], errs[i] = client.QueryOne(host)
+		}(i, client)
+	}
+	wg.Wait()
+
+	var (
+		answer   = &Answer{}
+		answered bool
+		firstErr error
+		targets  = make(map[string]string)
+		seen     = make(map[Record]bool)
+	)
+	for i, data := range results {
+		response := Response{Type: dns.TypeToString[clients[i].Options.QuestionTypes[0]]}
+		if data == nil || len(data.Resolver) == 0 {
+			// no server answered the question.
+			response.Error = errs[i]
+			if response.Error == nil {
+				response.Error = errNoAnswer
+			}
+			if firstErr == nil {
+				firstErr = response.Error
+			}
+			answer.Responses = append(answer.Responses, response)
+			continue
+		}
+		response.Rcode = data.StatusCodeRaw
+		answer.Responses = append(answer.Responses, response)
+
+		answer.IPs = append(answer.IPs, data.A...)
+		answer.IPv6 = append(answer.IPv6, data.AAAA...)
+		if !answered {
+			answered = true
+			// resolvers are recorded in the order they were tried, the last one answered.
+			answer.Resolver = data.Resolver[len(data.Resolver)-1]
+		}
+
+		for _, raw := range data.AllRecords {
+			record, ok := parseRecord(raw)
+			if !ok || seen[record] {
+				continue
+			}
+			seen[record] = true
+			if record.Type == "CNAME" {
+				targets[record.Name] = record.Value
+			}
+			answer.Records = append(answer.Records, record)
+		}
+	}
+	if !answered {
+		return nil, firstErr
+	}
+
+	// follow the chain from the queried name, guarding against loops.
+	name := strings.TrimSuffix(strings.ToLower(host), ".")
+	for i := 0; i < len(targets); i++ {
+		target, ok := targets[name]
+		if !ok {
+			break
+		}
+		answer.CNAMEs = append(answer.CNAMEs, target)
+		name = target
+	}
+
+	return answer, nil
+}
+
+// parseRecord parses an A, AAAA or CNAME record in zone file format.
+func parseRecord(raw string) (Record, bool) {
+	rr, err := dns.NewRR(raw)
+	if err != nil || rr == nil {
+		return Record{}, false
+	}
+	hdr := rr.Header()
+	record := Record{Name: strings.TrimSuffix(hdr.Name, "."), Type: dns.TypeToString[hdr.Rrtype], TTL: hdr.Ttl}
+	switch v := rr.(type) {
+	case *dns.A:
+		record.Value = v.A.String()
+	case *dns.AAAA:
+		record.Value = v.AAAA.String()
+	case *dns.CNAME:
+		record.Value = strings.TrimSuffix(v.Target, ".")
+	default:
+		return Record{}, false
+	}
+	return record, true
 }
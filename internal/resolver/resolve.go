@@ -1,12 +1,51 @@
 package resolver
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/miekg/dns"
+)
 
 const (
+	// Alive is a name that resolved to addresses.
 	Alive ResultType = iota + 1
+	// Error is a lookup that failed for any other reason.
 	Error
+	// NXDomain is a name that doesn't exist.
+	NXDomain
+	// NoData is a name that exists without A or AAAA records.
+	NoData
+	// ServFail is a lookup the DNS server failed to answer.
+	ServFail
+	// Refused is a lookup the DNS server refused to answer.
+	Refused
+	// Timeout is a lookup no DNS server answered in time.
+	Timeout
 )
 
+// String returns the name of the result type used in findings.
+func (t ResultType) String() string {
+	switch t {
+	case Alive:
+		return "alive"
+	case NXDomain:
+		return "nxdomain"
+	case NoData:
+		return "no_data"
+	case ServFail:
+		return "servfail"
+	case Refused:
+		return "refused"
+	case Timeout:
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
 // Pool is a pool of resolvers to resolve certificate names.
 type Pool struct {
 	*Resolver
@@ -15,8 +54,10 @@ type Pool struct {
 	wg      *sync.WaitGroup
 }
 
-// NewPool creates a pool of resolvers for resolving certificates.
+// NewPool creates a pool of resolvers for resolving certificates, with at
+// least one worker.
 func (r *Resolver) NewPool(workers int) *Pool {
+	workers = max(workers, 1)
 	resolutionPool := &Pool{
 		Resolver: r,
 		Tasks:    make(chan HostEntry),
@@ -39,17 +80,70 @@ func (r *Resolver) NewPool(workers int) *Pool {
 // resolve resolves a hostname to IP addresses.
 func (p *Pool) resolve() {
 	for task := range p.Tasks {
-		hosts, err := p.client.Lookup(task.Host)
+		answer, err := p.Query(task.Host)
 		if err != nil {
-			p.Results <- Result{Type: Error, Task: task, Error: err}
+			p.Results <- Result{Type: errorType(err), Task: task, Error: err}
 			continue
 		}
 
-		if len(hosts) == 0 {
-			continue
+		result := Result{
+			Type:     Alive,
+			Task:     task,
+			IPs:      answer.IPs,
+			IPv6:     answer.IPv6,
+			CNAMEs:   answer.CNAMEs,
+			Records:  answer.Records,
+			Resolver: answer.Resolver,
+		}
+		if len(answer.IPs) == 0 && len(answer.IPv6) == 0 {
+			result.Type, result.Error = classify(task.Host, answer.Responses)
 		}
 
-		p.Results <- Result{Type: Alive, Task: task, IPs: hosts}
+		p.Results <- result
 	}
 	p.wg.Done()
 }
+
+// classify returns the result type of a query without addresses from the
+// outcome of each question. NXDOMAIN applies to the name whatever the
+// question, a failed question could have held addresses, and only a name
+// with every question answered empty has no data.
+func classify(host string, responses []Response) (ResultType, error) {
+	for _, response := range responses {
+		if response.Error == nil && response.Rcode == dns.RcodeNameError {
+			return NXDomain, fmt.Errorf("resolver: %s: %s", host, dns.RcodeToString[response.Rcode])
+		}
+	}
+	for _, response := range responses {
+		switch {
+		case response.Error != nil:
+			return errorType(response.Error), response.Error
+		case response.Rcode != dns.RcodeSuccess:
+			return rcodeType(response.Rcode), fmt.Errorf("resolver: %s %s: %s", host, response.Type, dns.RcodeToString[response.Rcode])
+		}
+	}
+	return NoData, errNoAddresses
+}
+
+// rcodeType returns the result type of an unsuccessful response code.
+func rcodeType(rcode int) ResultType {
+	switch rcode {
+	case dns.RcodeNameError:
+		return NXDomain
+	case dns.RcodeServerFailure:
+		return ServFail
+	case dns.RcodeRefused:
+		return Refused
+	default:
+		return Error
+	}
+}
+
+// errorType returns the result type of a failed lookup.
+func errorType(err error) ResultType {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+	return Error
+}
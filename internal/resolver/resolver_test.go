@@ -0,0 +1,306 @@
+package resolver
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResolverTestSuite struct {
+	suite.Suite
+}
+
+// startServer starts a local DNS server on UDP and TCP answering from
+// records, which maps names to IPv4 or IPv6 addresses, a CNAME target, or a
+// marker: a response code such as SERVFAIL, or TIMEOUT to never answer. A
+// marker prefixed with a query type, as in AAAA:TIMEOUT, only applies to
+// that type. Names missing from records are NXDOMAIN. Names in flaky answer
+// SERVFAIL to their first A query.
+func (s *ResolverTestSuite) startServer(records map[string][]string, flaky ...string) string {
+	var (
+		mu      sync.Mutex
+		queries = make(map[string]int)
+	)
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg)
+		msg.SetReply(req)
+		q := req.Question[0]
+
+		if q.Qtype == dns.TypeA {
+			mu.Lock()
+			queries[q.Name]++
+			first := queries[q.Name] == 1
+			mu.Unlock()
+
+			for _, name := range flaky {
+				if first && q.Name == dns.Fqdn(name) {
+					msg.Rcode = dns.RcodeServerFailure
+					_ = w.WriteMsg(msg)
+					return
+				}
+			}
+		}
+
+		values, ok := records[q.Name]
+		if !ok {
+			msg.Rcode = dns.RcodeNameError
+		}
+		for _, value := range values {
+			switch response, _ := marker(value, q.Qtype); response {
+			case "":
+			case "TIMEOUT":
+				return
+			default:
+				msg.Rcode = dns.StringToRcode[response]
+			}
+		}
+
+		// answer like a recursive resolver, following CNAMEs.
+		for owner := q.Name; owner != "" && msg.Rcode == dns.RcodeSuccess; {
+			values := records[owner]
+			next := ""
+			for _, value := range values {
+				if _, ok := marker(value, q.Qtype); ok {
+					continue
+				}
+				ip := net.ParseIP(value)
+				switch {
+				case ip == nil:
+					next = dns.Fqdn(value)
+					msg.Answer = append(msg.Answer, &dns.CNAME{Hdr: header(owner, dns.TypeCNAME, 300), Target: next})
+				case ip.To4() != nil && q.Qtype == dns.TypeA:
+					msg.Answer = append(msg.Answer, &dns.A{Hdr: header(owner, dns.TypeA, 60), A: ip})
+				case ip.To4() == nil && q.Qtype == dns.TypeAAAA:
+					msg.Answer = append(msg.Answer, &dns.AAAA{Hdr: header(owner, dns.TypeAAAA, 60), AAAA: ip})
+				}
+			}
+			owner = next
+		}
+		_ = w.WriteMsg(msg)
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	listener, err := net.Listen("tcp", pc.LocalAddr().String())
+	s.Require().NoError(err)
+
+	udp := &dns.Server{PacketConn: pc, Handler: handler}
+	tcp := &dns.Server{Listener: listener, Handler: handler}
+	go func() { _ = udp.ActivateAndServe() }()
+	go func() { _ = tcp.ActivateAndServe() }()
+	s.T().Cleanup(func() {
+		_ = udp.Shutdown()
+		_ = tcp.Shutdown()
+	})
+
+	return pc.LocalAddr().String()
+}
+
+// marker returns the response a record value forces for a query of qtype,
+// empty if it doesn't apply to qtype, and whether the value is a marker.
+func marker(value string, qtype uint16) (string, bool) {
+	if prefix, rest, found := strings.Cut(value, ":"); found && dns.StringToType[prefix] != 0 {
+		if dns.StringToType[prefix] != qtype {
+			return "", true
+		}
+		value = rest
+	}
+	if value == "TIMEOUT" || dns.StringToRcode[value] != 0 {
+		return value, true
+	}
+	return "", false
+}
+
+// header returns a record header.
+func header(name string, rrtype uint16, ttl uint32) dns.RR_Header {
+	return dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl}
+}
+
+// TestLookup tests resolving against a local server over UDP and TCP.
+func (s *ResolverTestSuite) TestLookup() {
+	addr := s.startServer(map[string][]string{"www.example.test.": {"192.0.2.10"}})
+
+	for _, upstream := range []string{addr, "tcp://" + addr} {
+		r, err := New(Options{Resolvers: []string{upstream}})
+		s.Require().NoError(err)
+
+		ips, err := r.Lookup("www.example.test")
+		s.Require().NoError(err, upstream)
+		s.Equal([]string{"192.0.2.10"}, ips)
+
+		_, err = r.Lookup("missing.example.test")
+		s.Error(err)
+	}
+}
+
+// TestQuery tests resolving IPv4 and IPv6 addresses through a CNAME chain.
+func (s *ResolverTestSuite) TestQuery() {
+	addr := s.startServer(map[string][]string{
+		"www.example.test.":    {"edge.example.test"},
+		"edge.example.test.":   {"example.cdn.test"},
+		"example.cdn.test.":    {"192.0.2.20", "2001:db8::20"},
+		"v6only.example.test.": {"2001:db8::21"},
+	})
+
+	r, err := New(Options{Resolvers: []string{addr}})
+	s.Require().NoError(err)
+
+	answer, err := r.Query("www.example.test")
+	s.Require().NoError(err)
+	s.Equal([]string{"192.0.2.20"}, answer.IPs)
+	s.Equal([]string{"2001:db8::20"}, answer.IPv6)
+	s.Equal([]string{"edge.example.test", "example.cdn.test"}, answer.CNAMEs)
+	s.Equal(addr, answer.Resolver)
+	s.Contains(answer.Records, Record{Name: "www.example.test", Type: "CNAME", Value: "edge.example.test", TTL: 300})
+	s.Contains(answer.Records, Record{Name: "example.cdn.test", Type: "AAAA", Value: "2001:db8::20", TTL: 60})
+
+	answer, err = r.Query("v6only.example.test")
+	s.Require().NoError(err)
+	s.Empty(answer.IPs)
+	s.Equal([]string{"2001:db8::21"}, answer.IPv6)
+}
+
+// TestQueryPartial tests that a failed AAAA question keeps the A answer.
+func (s *ResolverTestSuite) TestQueryPartial() {
+	addr := s.startServer(map[string][]string{
+		"slow6.example.test.":   {"192.0.2.22", "AAAA:TIMEOUT"},
+		"broken6.example.test.": {"192.0.2.23", "AAAA:SERVFAIL"},
+		"silent.example.test.":  {"TIMEOUT"},
+	})
+
+	r, err := New(Options{Resolvers: []string{addr}})
+	s.Require().NoError(err)
+
+	answer, err := r.Query("slow6.example.test")
+	s.Require().NoError(err)
+	s.Equal([]string{"192.0.2.22"}, answer.IPs)
+	s.Empty(answer.IPv6)
+	s.Equal(addr, answer.Resolver)
+
+	answer, err = r.Query("broken6.example.test")
+	s.Require().NoError(err)
+	s.Equal([]string{"192.0.2.23"}, answer.IPs)
+
+	// nothing answered at all.
+	_, err = r.Query("silent.example.test")
+	s.Error(err)
+}
+
+// TestRetries tests that lookups are retried up to the configured count.
+func (s *ResolverTestSuite) TestRetries() {
+	addr := s.startServer(map[string][]string{
+		"once.example.test.":  {"192.0.2.11"},
+		"twice.example.test.": {"192.0.2.12"},
+	}, "once.example.test", "twice.example.test")
+
+	r, err := New(Options{Resolvers: []string{addr}, Retries: 1})
+	s.Require().NoError(err)
+	_, err = r.Lookup("once.example.test")
+	s.Error(err)
+
+	r, err = New(Options{Resolvers: []string{addr}, Retries: 2})
+	s.Require().NoError(err)
+	ips, err := r.Lookup("twice.example.test")
+	s.Require().NoError(err)
+	s.Equal([]string{"192.0.2.12"}, ips)
+}
+
+// TestPool tests the result type of each kind of answer.
+func (s *ResolverTestSuite) TestPool() {
+	addr := s.startServer(map[string][]string{
+		"www.example.test.":     {"192.0.2.30"},
+		"v6only.example.test.":  {"2001:db8::30"},
+		"empty.example.test.":   {},
+		"broken.example.test.":  {"SERVFAIL"},
+		"refused.example.test.": {"REFUSED"},
+		"silent.example.test.":  {"TIMEOUT"},
+		// a failed AAAA question doesn't hide the A answer.
+		"slow6.example.test.":   {"192.0.2.31", "AAAA:TIMEOUT"},
+		"broken6.example.test.": {"192.0.2.32", "AAAA:SERVFAIL"},
+		// nor does an empty A answer hide the failed AAAA question.
+		"empty4.example.test.": {"AAAA:SERVFAIL"},
+		"quiet6.example.test.": {"AAAA:TIMEOUT"},
+	})
+
+	r, err := New(Options{Resolvers: []string{addr}})
+	s.Require().NoError(err)
+
+	want := map[string]ResultType{
+		"www.example.test":     Alive,
+		"v6only.example.test":  Alive,
+		"slow6.example.test":   Alive,
+		"broken6.example.test": Alive,
+		"empty4.example.test":  ServFail,
+		"quiet6.example.test":  Timeout,
+		"missing.example.test": NXDomain,
+		"empty.example.test":   NoData,
+		"broken.example.test":  ServFail,
+		"refused.example.test": Refused,
+		"silent.example.test":  Timeout,
+	}
+
+	pool := r.NewPool(len(want))
+	go func() {
+		for host := range want {
+			pool.Tasks <- HostEntry{Host: host}
+		}
+		close(pool.Tasks)
+	}()
+
+	got := make(map[string]ResultType)
+	for result := range pool.Results {
+		got[result.Task.Host] = result.Type
+		if result.Type == Alive {
+			s.NoError(result.Error, result.Task.Host)
+		} else {
+			s.Error(result.Error, result.Task.Host)
+		}
+	}
+	s.Equal(want, got)
+	s.Equal("nxdomain", NXDomain.String())
+
+	// a pool without workers still resolves with one.
+	pool = r.NewPool(0)
+	go func() {
+		pool.Tasks <- HostEntry{Host: "www.example.test"}
+		close(pool.Tasks)
+	}()
+	result := <-pool.Results
+	s.Equal(Alive, result.Type)
+	_, open := <-pool.Results
+	s.False(open)
+}
+
+// TestParseUpstream tests normalizing DNS server specs.
+func (s *ResolverTestSuite) TestParseUpstream() {
+	for spec, want := range map[string]string{
+		"1.1.1.1":                       "udp:1.1.1.1:53",
+		"1.1.1.1:5353":                  "udp:1.1.1.1:5353",
+		"udp://8.8.8.8":                 "udp:8.8.8.8:53",
+		"tcp://8.8.8.8:53":              "tcp:8.8.8.8:53",
+		"tls://1.1.1.1":                 "dot:1.1.1.1:853",
+		"tls://dns.google:8853":         "dot:dns.google:8853",
+		"[2606:4700:4700::1111]":        "udp:[2606:4700:4700::1111]:53",
+		"https://dns.google/dns-query":  "doh:https://dns.google/dns-query",
+		"dot:9.9.9.9:853":               "dot:9.9.9.9:853",
+		"doh:https://1.1.1.1/dns-query": "doh:https://1.1.1.1/dns-query",
+	} {
+		got, err := ParseUpstream(spec)
+		s.Require().NoError(err, spec)
+		s.Equal(want, got, spec)
+	}
+
+	for _, spec := range []string{"", "quic://1.1.1.1", "tls://"} {
+		_, err := ParseUpstream(spec)
+		s.Error(err, spec)
+	}
+}
+
+// TestRunResolverTestSuite runs the test suite.
+func TestRunResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
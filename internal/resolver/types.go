@@ -15,10 +15,56 @@ type (
 		Task HostEntry
 		// IPs is the resolved IP address for the host.
 		IPs []string
+		// IPv6 are the resolved IPv6 addresses for the host.
+		IPv6 []string
+		// CNAMEs is the CNAME chain followed from the host, in order.
+		CNAMEs []string
+		// Records are the A, AAAA and CNAME records answered, with their TTLs.
+		Records []Record
+		// Resolver is the DNS server that answered.
+		Resolver string
 		// Error is the error that occurred during resolution.
 		Error error
 	}
 
+	// Answer is the answer to an address query.
+	Answer struct {
+		// IPs are the IPv4 addresses.
+		IPs []string
+		// IPv6 are the IPv6 addresses.
+		IPv6 []string
+		// CNAMEs is the CNAME chain followed, in order.
+		CNAMEs []string
+		// Records are the A, AAAA and CNAME records answered.
+		Records []Record
+		// Resolver is the DNS server that answered.
+		Resolver string
+		// Responses are the outcomes of the A and AAAA questions.
+		Responses []Response
+	}
+
+	// Response is the outcome of a single question of a query.
+	Response struct {
+		// Type is the question type, e.g. AAAA.
+		Type string
+		// Rcode is the response code, e.g. dns.RcodeNameError, if a server answered.
+		Rcode int
+		// Error is the error of the question if no server answered.
+		Error error
+	}
+
+	// Record is a DNS record in an answer.
+	Record struct {
+		// Name is the owner name of the record.
+		Name string `json:"name"`
+		// Type is the record type, e.g. A.
+		Type string `json:"type"`
+		// Value is the address or CNAME target.
+		Value string `json:"value"`
+		// TTL is the record time to live in seconds.
+		TTL uint32 `json:"ttl"`
+	}
+
 	// HostEntry defines a host with the source
 	HostEntry struct {
 		// Hostname is an SSL name found in a certificate.
@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ParseUpstream normalizes a DNS server spec into the form dnsx expects:
+//
+//	1.1.1.1, 1.1.1.1:53, udp://1.1.1.1   plain DNS over UDP
+//	tcp://1.1.1.1:53                      plain DNS over TCP
+//	tls://1.1.1.1, tls://dns.google:853   DNS over TLS
+//	https://dns.google/dns-query          DNS over HTTPS
+//
+// Specs already in dnsx form, such as "dot:1.1.1.1:853", are kept.
+func ParseUpstream(spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return "", fmt.Errorf("resolver: empty upstream")
+	}
+
+	// dnsx form, e.g. dot:1.1.1.1:853 or doh:https://...
+	for _, prefix := range []string{"udp:", "tcp:", "dot:", "doh:"} {
+		if strings.HasPrefix(spec, prefix) && !strings.HasPrefix(spec, prefix+"//") {
+			return spec, nil
+		}
+	}
+
+	scheme, rest, found := strings.Cut(spec, "://")
+	if !found {
+		scheme, rest = "udp", spec
+	}
+
+	switch scheme {
+	case "udp", "tcp":
+		host, err := hostPort(rest, "53")
+		if err != nil {
+			return "", err
+		}
+		return scheme + ":" + host, nil
+	case "tls":
+		host, err := hostPort(rest, "853")
+		if err != nil {
+			return "", err
+		}
+		return "dot:" + host, nil
+	case "https":
+		if _, err := url.Parse(spec); err != nil {
+			return "", fmt.Errorf("resolver: %w", err)
+		}
+		return "doh:" + spec, nil
+	default:
+		return "", fmt.Errorf("resolver: unsupported upstream %q", spec)
+	}
+}
+
+// ParseUpstreams normalizes every spec with ParseUpstream.
+func ParseUpstreams(specs []string) ([]string, error) {
+	upstreams := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		upstream, err := ParseUpstream(spec)
+		if err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+	return upstreams, nil
+}
+
+// LoadUpstreams reads DNS server specs from a file, one per line. Blank lines
+// and comments starting with # are skipped.
+func LoadUpstreams(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolver: %w", err)
+	}
+	defer file.Close()
+
+	var specs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		specs = append(specs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("resolver: %w", err)
+	}
+
+	return ParseUpstreams(specs)
+}
+
+// hostPort adds the default port to addr if it has none.
+func hostPort(addr, port string) (string, error) {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr, nil
+	}
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if addr == "" || strings.ContainsAny(addr, "/ ") {
+		return "", fmt.Errorf("resolver: invalid address %q", addr)
+	}
+	return net.JoinHostPort(addr, port), nil
+}
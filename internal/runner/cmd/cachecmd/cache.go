@@ -0,0 +1,113 @@
+package cachecmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/certsio/certsio/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+type Config struct {
+	ttl time.Duration
+}
+
+type Command struct {
+	cmd    *cobra.Command
+	config *Config
+}
+
+func New() *Command {
+	c := &Command{
+		config: &Config{},
+	}
+	c.cmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the search response cache.",
+	}
+	c.cmd.PersistentFlags().DurationVar(&c.config.ttl, "cache-ttl", 0, "entries older than this are expired (default is cache_ttl from the config file)")
+
+	c.cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List cached responses",
+		Run:   c.list,
+	})
+	c.cmd.AddCommand(&cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired cached responses",
+		Run:   c.prune,
+	})
+	c.cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached responses",
+		Run:   c.clear,
+	})
+	return c
+}
+
+// Command returns the cobra command.
+func (c *Command) Command() *cobra.Command {
+	return c.cmd
+}
+
+// list prints the cached responses.
+func (c *Command) list(cmd *cobra.Command, args []string) {
+	cache := c.open(cmd)
+	entries, err := cache.Entries()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "URL\tFIELD\tTERM\tPAGE\tAGE\tSIZE\tEXPIRED")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%t\n",
+			entry.BaseURL, entry.Query.Field, entry.Query.Value, entry.Query.Page,
+			time.Since(entry.CreatedAt).Round(time.Second), entry.Size, entry.Expired)
+	}
+	w.Flush()
+}
+
+// prune removes expired cached responses.
+func (c *Command) prune(cmd *cobra.Command, args []string) {
+	cache := c.open(cmd)
+	removed, err := cache.Prune()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("removed %d expired entries from %s", removed, cache.Dir())
+}
+
+// clear removes all cached responses.
+func (c *Command) clear(cmd *cobra.Command, args []string) {
+	cache := c.open(cmd)
+	removed, err := cache.Clear()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("removed %d entries from %s", removed, cache.Dir())
+}
+
+// open opens the cache configured in the config file.
+func (c *Command) open(cmd *cobra.Command) *search.Cache {
+	cfgFile, err := cmd.Root().Flags().GetString("config")
+	if err != nil {
+		log.Fatalf("couldn't get config file: %v", err)
+	}
+
+	// the cache doesn't need an API key, so a missing config file is fine.
+	cfg, _ := config.Get(cfgFile)
+	if c.config.ttl > 0 {
+		cfg.CacheTTL = c.config.ttl
+	}
+
+	cache, err := search.NewCacheFromConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return cache
+}
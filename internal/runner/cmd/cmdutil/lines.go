@@ -0,0 +1,40 @@
+// Package cmdutil holds helpers shared by the commands.
+package cmdutil
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadLines reads the lines of path, or stdin if path is "-". Lines are
+// trimmed, and blank lines, comments starting with # and repeated lines are
+// skipped.
+func ReadLines(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var (
+		lines []string
+		seen  = make(map[string]bool)
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
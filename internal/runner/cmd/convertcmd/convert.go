@@ -0,0 +1,132 @@
+package convertcmd
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// extensions are the file extensions read when converting a directory.
+var extensions = []string{".pem", ".crt", ".cer", ".der"}
+
+type Config struct {
+	server   string
+	leafOnly bool
+}
+
+type Command struct {
+	cmd    *cobra.Command
+	config *Config
+}
+
+func New() *Command {
+	c := &Command{
+		config: &Config{},
+	}
+	c.cmd = &cobra.Command{
+		Use:   "convert <file or directory>...",
+		Short: "Convert PEM or DER certificates to certsio JSONL.",
+		Long: `Convert PEM or DER certificates to certsio JSONL, the format the search command writes.
+Directories are searched recursively for ` + strings.Join(extensions, ", ") + ` files.`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  c.Run,
+	}
+	c.cmd.Flags().StringVar(&c.config.server, "server", "", "server to record for the certificates, e.g. 10.0.0.1:443")
+	c.cmd.Flags().BoolVar(&c.config.leafOnly, "leaf-only", false, "only convert the first certificate of each file")
+	return c
+}
+
+// Command returns the cobra command.
+func (c *Command) Command() *cobra.Command {
+	return c.cmd
+}
+
+// Run executes the command.
+func (c *Command) Run(cmd *cobra.Command, args []string) {
+	outputFile, err := cmd.Flags().GetString("output")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file := os.Stdout
+	if outputFile != "" {
+		file, err = os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("couldn't create output file: %v", err)
+		}
+	}
+	defer file.Close()
+	writer := output.NewWriter(file)
+
+	var converted, failed int
+	for _, arg := range args {
+		paths, err := certificateFiles(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, path := range paths {
+			certs, err := certificate.ReadFile(path)
+			if err == nil && len(certs) == 0 {
+				err = errors.New("no certificates found")
+			}
+			if err != nil {
+				log.Printf("%s: %v", path, err)
+				failed++
+				continue
+			}
+			if c.config.leafOnly {
+				certs = certs[:1]
+			}
+
+			timestamp := time.Now().UTC().Format(time.RFC3339)
+			for _, x509Cert := range certs {
+				cert := certificate.FromX509(x509Cert, c.config.server)
+				cert.Timestamp = timestamp
+				if err := writer.Write(cert); err != nil {
+					log.Fatalf("couldn't write certificate: %v", err)
+				}
+				converted++
+			}
+		}
+	}
+
+	log.Printf("converted %d certificates, %d files failed", converted, failed)
+}
+
+// certificateFiles returns path if it is a file, or the certificate files in it if it is a directory.
+func certificateFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, e := range extensions {
+			if ext == e {
+				paths = append(paths, path)
+				break
+			}
+		}
+		return nil
+	})
+	return paths, err
+}
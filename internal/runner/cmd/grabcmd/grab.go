@@ -0,0 +1,122 @@
+package grabcmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/certsio/certsio/internal/runner/cmd/cmdutil"
+	"github.com/certsio/certsio/pkg/grab"
+	"github.com/certsio/certsio/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+type Config struct {
+	inputFile   string
+	serverName  string
+	noSNI       bool
+	timeout     time.Duration
+	concurrency int
+	chain       bool
+}
+
+type Command struct {
+	cmd    *cobra.Command
+	config *Config
+}
+
+func New() *Command {
+	c := &Command{
+		config: &Config{},
+	}
+	c.cmd = &cobra.Command{
+		Use:   "grab [host:port]...",
+		Short: "Fetch the certificates TLS servers serve now.",
+		Long: `Fetch the certificates TLS servers serve now and write them in certsio JSONL format,
+to compare against what certs.io saw. Targets are read from the arguments, the --input file or stdin.`,
+		Run: c.Run,
+	}
+	c.cmd.Flags().StringVarP(&c.config.inputFile, "input", "i", "", "file of targets, one per line (- for stdin)")
+	c.cmd.Flags().StringVar(&c.config.serverName, "sni", "", "SNI to send instead of the target host")
+	c.cmd.Flags().BoolVar(&c.config.noSNI, "no-sni", false, "don't send SNI")
+	c.cmd.Flags().DurationVar(&c.config.timeout, "timeout", 10*time.Second, "timeout for connecting to and handshaking with each server")
+	c.cmd.Flags().IntVar(&c.config.concurrency, "concurrency", 10, "number of servers to grab in parallel")
+	c.cmd.Flags().BoolVar(&c.config.chain, "chain", false, "also write the intermediate certificates served after the leaf")
+	return c
+}
+
+// Command returns the cobra command.
+func (c *Command) Command() *cobra.Command {
+	return c.cmd
+}
+
+// Run executes the command.
+func (c *Command) Run(cmd *cobra.Command, args []string) {
+	targets := args
+	if c.config.inputFile != "" || len(args) == 0 {
+		inputFile := c.config.inputFile
+		if inputFile == "" {
+			inputFile = "-"
+		}
+		var err error
+		if targets, err = cmdutil.ReadLines(inputFile); err != nil {
+			log.Fatalf("couldn't read targets: %v", err)
+		}
+		targets = append(args, targets...)
+	}
+	if len(targets) == 0 {
+		log.Fatal("no targets specified.")
+	}
+
+	outputFile, err := cmd.Flags().GetString("output")
+	if err != nil {
+		log.Fatal(err)
+	}
+	file := os.Stdout
+	if outputFile != "" {
+		file, err = os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("couldn't create output file: %v", err)
+		}
+	}
+	defer file.Close()
+	writer := output.NewWriter(file)
+
+	grabber := grab.New(grab.Config{
+		Timeout:     c.config.timeout,
+		Concurrency: c.config.concurrency,
+		ServerName:  c.config.serverName,
+		NoSNI:       c.config.noSNI,
+		Chain:       c.config.chain,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resultChan := make(chan grab.Result)
+	go func() {
+		grabber.GrabAll(ctx, targets, resultChan)
+		close(resultChan)
+	}()
+
+	var failed int
+	for result := range resultChan {
+		if result.Err != nil {
+			log.Print(result.Err)
+			failed++
+			continue
+		}
+		for _, cert := range result.Certificates {
+			if err := writer.Write(cert); err != nil {
+				log.Fatalf("couldn't write certificate: %v", err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		log.Printf("%d of %d targets failed", failed, len(targets))
+	}
+}
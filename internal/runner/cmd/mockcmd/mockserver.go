@@ -0,0 +1,82 @@
+package mockcmd
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/certsio/certsio/pkg/mockserver"
+	"github.com/spf13/cobra"
+)
+
+type Config struct {
+	dataFile         string
+	listen           string
+	pageSize         int
+	apiKey           string
+	rateLimitRate    float64
+	unauthorizedRate float64
+	latency          time.Duration
+	seed             int64
+}
+
+type Command struct {
+	cmd    *cobra.Command
+	config *Config
+}
+
+func New() *Command {
+	c := &Command{
+		config: &Config{},
+	}
+	c.cmd = &cobra.Command{
+		Use:   "mock-server",
+		Short: "Serve a local mock of the certs.io API.",
+		Long: `Serve a local mock of the certs.io API from a file of certificates in certsio JSONL format.
+Point base_url in the config file at http://<listen>/certificates to search it without spending API credits.`,
+		Run: c.Run,
+	}
+	c.cmd.Flags().StringVarP(&c.config.dataFile, "data", "d", "", "file containing certificates in certsio JSONL format")
+	c.cmd.Flags().StringVarP(&c.config.listen, "listen", "l", "127.0.0.1:8080", "address to listen on")
+	c.cmd.Flags().IntVar(&c.config.pageSize, "page-size", 100, "number of certificates per page")
+	c.cmd.Flags().StringVar(&c.config.apiKey, "api-key", "", "API key requests must send (default accepts any key)")
+	c.cmd.Flags().Float64Var(&c.config.rateLimitRate, "rate-limit-rate", 0, "fraction of requests answered with 429 Too Many Requests")
+	c.cmd.Flags().Float64Var(&c.config.unauthorizedRate, "unauthorized-rate", 0, "fraction of requests answered with 401 Unauthorized")
+	c.cmd.Flags().DurationVar(&c.config.latency, "latency", 0, "latency added to every response")
+	c.cmd.Flags().Int64Var(&c.config.seed, "seed", time.Now().UnixNano(), "seed for the injected failures")
+	return c
+}
+
+// Command returns the cobra command.
+func (c *Command) Command() *cobra.Command {
+	return c.cmd
+}
+
+// Run executes the command.
+func (c *Command) Run(cmd *cobra.Command, args []string) {
+	if c.config.dataFile == "" {
+		log.Fatal("no data file specified.")
+	}
+	file, err := os.Open(c.config.dataFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	certs, err := mockserver.Load(file)
+	file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := mockserver.New(certs, mockserver.Config{
+		PageSize:         c.config.pageSize,
+		APIKey:           c.config.apiKey,
+		RateLimitRate:    c.config.rateLimitRate,
+		UnauthorizedRate: c.config.unauthorizedRate,
+		Latency:          c.config.latency,
+		Seed:             c.config.seed,
+	})
+
+	log.Printf("serving %d certificates on http://%s/certificates", len(certs), c.config.listen)
+	log.Fatal(http.ListenAndServe(c.config.listen, server))
+}
@@ -1,15 +1,30 @@
 package resolvecmd
 
 import (
+	"context"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/certsio/certsio/pkg/certresolve"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/certsio/certsio/pkg/output"
 	"github.com/spf13/cobra"
 )
 
 type Config struct {
-	inputFile string
+	inputFile     string
+	workers       int
+	resolvers     []string
+	resolversFile string
+	retries       int
+	format        string
+	verify        bool
+	verifyHTTP    bool
+	verifyTimeout time.Duration
 }
 type Command struct {
 	cmd    *cobra.Command
@@ -23,10 +38,21 @@ func New() *Command {
 	c.cmd = &cobra.Command{
 		Use:   "resolve",
 		Short: "Resolve the ssl_names within a certificate.",
-		Long:  "Resolve the ssl_names within a certificate. Find potential origin bypasses or interesting certificates.",
-		Run:   c.Run,
+		Long: `Resolve the ssl_names within a certificate. Find potential origin bypasses or interesting certificates.
+Certificates are read in certsio JSONL format from the --input file or stdin, e.g.
+
+  certsio search domain example.com | certsio resolve`,
+		Run: c.Run,
 	}
-	c.cmd.PersistentFlags().StringVarP(&c.config.inputFile, "input", "i", "", "input file containing TLS certificates")
+	c.cmd.PersistentFlags().StringVarP(&c.config.inputFile, "input", "i", "-", "input file containing TLS certificates (- for stdin)")
+	c.cmd.PersistentFlags().IntVar(&c.config.workers, "workers", 10, "number of names to resolve in parallel")
+	c.cmd.PersistentFlags().StringSliceVar(&c.config.resolvers, "resolvers", nil, "comma-separated DNS servers to query: host[:port], tls://host[:port] or https://host/path (default is resolvers from the config file, or a set of public resolvers)")
+	c.cmd.PersistentFlags().StringVar(&c.config.resolversFile, "resolvers-file", "", "file of DNS servers to query, one per line")
+	c.cmd.PersistentFlags().IntVar(&c.config.retries, "retries", 0, "number of attempts for each lookup (default is dns_retries from the config file, or 1)")
+	c.cmd.PersistentFlags().StringVarP(&c.config.format, "format", "f", string(output.FormatJSONL), "findings format: jsonl, csv, tsv or table")
+	c.cmd.PersistentFlags().BoolVar(&c.config.verify, "verify", false, "confirm findings by connecting to the certificate's server with the name as SNI and comparing certificates")
+	c.cmd.PersistentFlags().BoolVar(&c.config.verifyHTTP, "verify-http", false, "also compare the HTTP responses of the certificate's server and the resolved addresses (implies --verify)")
+	c.cmd.PersistentFlags().DurationVar(&c.config.verifyTimeout, "verify-timeout", 10*time.Second, "timeout for each verification handshake and HTTP request")
 	return c
 }
 
@@ -37,25 +63,65 @@ func (c *Command) Command() *cobra.Command {
 
 // Run executes the command.
 func (c *Command) Run(cmd *cobra.Command, args []string) {
-	var (
-		file *os.File
-		err  error
-	)
-	if c.config.inputFile == "" {
-		log.Fatal("no input file specified.")
-	}
-	file, err = os.Open(c.config.inputFile)
+	if c.config.workers < 1 {
+		log.Fatalf("--workers must be at least 1")
+	}
+
+	var in io.Reader = os.Stdin
+	if c.config.inputFile != "" && c.config.inputFile != "-" {
+		file, err := os.Open(c.config.inputFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	outputFile, err := cmd.Flags().GetString("output")
+	if err != nil {
+		log.Fatal(err)
+	}
+	out := os.Stdout
+	if outputFile != "" {
+		out, err = os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("couldn't create output file: %v", err)
+		}
+		defer out.Close()
+	}
+	writer, err := certresolve.NewFindingWriter(out, output.Format(c.config.format))
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
 
-	resolver, err := certresolve.New(&certresolve.Config{
-		WorkerCount: 10,
-	})
+	cfgFile, err := cmd.Root().Flags().GetString("config")
 	if err != nil {
+		log.Fatalf("couldn't get config file: %v", err)
+	}
+	// resolving doesn't need an API key, so a missing config file is fine.
+	cfg, _ := config.Get(cfgFile)
+
+	resolverConfig := &certresolve.Config{
+		Writer:      writer,
+		WorkerCount: c.config.workers,
+	}
+	if c.config.verify || c.config.verifyHTTP {
+		resolverConfig.Verifier = certresolve.NewVerifier(certresolve.VerifyOptions{
+			Timeout: c.config.verifyTimeout,
+			HTTP:    c.config.verifyHTTP,
+		})
+	}
+	if err := resolverConfig.ConfigureResolvers(c.config.resolvers, c.config.resolversFile, c.config.retries, cfg); err != nil {
 		log.Fatal(err)
 	}
+	resolver, err := certresolve.New(resolverConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// stop verifying findings on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	resolver.Start(file)
+	resolver.Start(ctx, in)
 }
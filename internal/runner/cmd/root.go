@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"github.com/certsio/certsio/internal/runner/cmd/cachecmd"
+	"github.com/certsio/certsio/internal/runner/cmd/convertcmd"
+	"github.com/certsio/certsio/internal/runner/cmd/grabcmd"
+	"github.com/certsio/certsio/internal/runner/cmd/mockcmd"
+	"github.com/certsio/certsio/internal/runner/cmd/resolvecmd"
 	"github.com/certsio/certsio/internal/runner/cmd/searchcmd"
 	"github.com/spf13/cobra"
 )
@@ -25,7 +30,11 @@ func init() {
 
 	rootCmd.AddCommand(searchcmd.New(&rootConfig.searchOpts))
 	rootCmd.AddCommand(versionCmd)
-	//rootCmd.AddCommand(resolvecmd.New().Command())
+	rootCmd.AddCommand(mockcmd.New().Command())
+	rootCmd.AddCommand(cachecmd.New().Command())
+	rootCmd.AddCommand(convertcmd.New().Command())
+	rootCmd.AddCommand(grabcmd.New().Command())
+	rootCmd.AddCommand(resolvecmd.New().Command())
 }
 
 // Execute executes the root command.
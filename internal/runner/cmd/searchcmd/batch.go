@@ -0,0 +1,75 @@
+package searchcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/certsio/certsio/internal/runner/cmd/cmdutil"
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/certsio/certsio/pkg/search"
+)
+
+// runBatch searches field for every term in the input file.
+func (s *Search) runBatch(cfg config.Config, field search.Field) {
+	if s.opts.checkpointFile != "" {
+		log.Fatal("--checkpoint is not supported with --input")
+	}
+
+	terms, err := cmdutil.ReadLines(s.opts.inputFile)
+	if err != nil {
+		log.Fatalf("couldn't read search terms: %v", err)
+	}
+	if len(terms) == 0 {
+		log.Fatal("no search terms in input")
+	}
+
+	s.runSearch(cfg, nil, func(ctx context.Context, client *search.Client, resultChan chan<- []certificate.Certificate) error {
+		results := client.StreamBatch(ctx, field, terms, s.opts.workers, resultChan)
+		return printSummary(os.Stderr, results)
+	})
+}
+
+// printSummary writes the per-term totals and failures of a batch. Failed
+// terms are only reported, the batch fails if every term failed, or with the
+// first error that would fail every term such as a rejected API key.
+func printSummary(out io.Writer, results []search.TermResult) error {
+	var (
+		firstErr error
+		fatalErr error
+		failed   int
+	)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TERM\tTOTAL\tRECEIVED\tERROR")
+	for _, result := range results {
+		errMsg := "-"
+		if result.Err != nil {
+			failed++
+			errMsg = result.Err.Error()
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+			if fatalErr == nil && (errors.Is(result.Err, search.ErrUnauthorized) || errors.Is(result.Err, context.Canceled)) {
+				fatalErr = result.Err
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", result.Term, result.Total, result.Received, errMsg)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "searched %d terms, %d failed\n", len(results), failed)
+
+	switch {
+	case fatalErr != nil:
+		return fatalErr
+	case failed > 0 && failed == len(results):
+		return firstErr
+	default:
+		return nil
+	}
+}
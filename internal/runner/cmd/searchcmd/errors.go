@@ -0,0 +1,49 @@
+package searchcmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/certsio/certsio/pkg/search"
+)
+
+// Exit codes for each class of search failure.
+const (
+	exitError          = 1
+	exitUnauthorized   = 3
+	exitRateLimited    = 4
+	exitBadRequest     = 5
+	exitUnexpectedCode = 6
+	exitInterrupted    = 130
+)
+
+// exitOnError prints a message for the class of err and exits with its exit code.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+
+	var apiErr *search.APIError
+	switch {
+	case errors.Is(err, context.Canceled):
+		log.Printf("search interrupted, results received so far were written")
+		os.Exit(exitInterrupted)
+	case errors.Is(err, search.ErrUnauthorized):
+		log.Printf("the API rejected your API key, check api_key in your config file: %v", err)
+		os.Exit(exitUnauthorized)
+	case errors.Is(err, search.ErrRateLimited):
+		log.Printf("the API is rate limiting requests, try again later or lower --rate-limit: %v", err)
+		os.Exit(exitRateLimited)
+	case errors.Is(err, search.ErrBadRequest):
+		log.Printf("the API rejected the search request: %v", err)
+		os.Exit(exitBadRequest)
+	case errors.As(err, &apiErr):
+		log.Printf("the API returned an unexpected response: %v: %s", err, apiErr.Body)
+		os.Exit(exitUnexpectedCode)
+	default:
+		log.Printf("search failed: %v", err)
+		os.Exit(exitError)
+	}
+}
@@ -0,0 +1,47 @@
+package searchcmd
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/certsio/certsio/pkg/filter"
+)
+
+// newFilter builds the filter for the filter flags given on the command line.
+func (s *Search) newFilter() (filter.Filter, error) {
+	var filters []filter.Filter
+
+	if s.opts.excludeExpired {
+		filters = append(filters, filter.Not(filter.Expired()))
+	}
+	if s.opts.selfSignedOnly {
+		filters = append(filters, filter.SelfSigned())
+	}
+	if s.opts.issuedAfter != "" {
+		t, err := filter.ParseTime(s.opts.issuedAfter)
+		if err != nil {
+			return nil, fmt.Errorf("--issued-after: %w", err)
+		}
+		filters = append(filters, filter.IssuedAfter(t))
+	}
+	if s.opts.expiresWithin != "" {
+		d, err := filter.ParseDuration(s.opts.expiresWithin)
+		if err != nil {
+			return nil, fmt.Errorf("--expires-within: %w", err)
+		}
+		filters = append(filters, filter.ExpiresWithin(d, time.Now()))
+	}
+	if s.opts.issuerOrg != "" {
+		filters = append(filters, filter.IssuerOrg(s.opts.issuerOrg))
+	}
+	if s.opts.nameRegex != "" {
+		re, err := regexp.Compile(s.opts.nameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("--name-regex: %w", err)
+		}
+		filters = append(filters, filter.NameMatches(re))
+	}
+
+	return filter.All(filters...), nil
+}
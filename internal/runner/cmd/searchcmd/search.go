@@ -1,13 +1,50 @@
 package searchcmd
 
 import (
+	"time"
+
+	"github.com/certsio/certsio/pkg/output"
 	"github.com/certsio/certsio/pkg/search"
 	"github.com/spf13/cobra"
 )
 
 type Options struct {
-	maxPages   uint64
-	OutputFile string
+	maxPages       uint64
+	inputFile      string
+	workers        int
+	concurrency    int
+	unordered      bool
+	rateLimit      float64
+	burst          int
+	recordDir      string
+	cacheTTL       time.Duration
+	noCache        bool
+	replayDir      string
+	checkpointFile string
+	resumeFile     string
+	format         string
+	template       string
+	joiner         string
+	fields         []string
+	unique         string
+	uniqueDisk     bool
+	groupBy        string
+	privateSuffix  bool
+	dedupe         bool
+	dedupeServer   bool
+	dedupeSpill    int
+	resolve        bool
+	resolveWorkers int
+	resolvers      []string
+	resolversFile  string
+	dnsRetries     int
+	excludeExpired bool
+	selfSignedOnly bool
+	issuedAfter    string
+	expiresWithin  string
+	issuerOrg      string
+	nameRegex      string
+	OutputFile     string
 }
 
 type Search struct {
@@ -27,8 +64,44 @@ func New(o *Options) *cobra.Command {
 		opts: o,
 		cmd:  searchCmd,
 	}
+	searcher.cmd.Run = searcher.runResume
+
 	// add flags
 	searcher.cmd.PersistentFlags().Uint64VarP(&searcher.opts.maxPages, "max-pages", "m", 0, "maximum number of pages to return (0 for all pages)")
+	searcher.cmd.PersistentFlags().StringVarP(&searcher.opts.inputFile, "input", "i", "", "file of search terms, one per line (- for stdin)")
+	searcher.cmd.PersistentFlags().IntVar(&searcher.opts.workers, "workers", 4, "number of terms from --input searched in parallel")
+	searcher.cmd.PersistentFlags().IntVar(&searcher.opts.concurrency, "concurrency", 1, "number of pages to fetch in parallel")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.unordered, "unordered", false, "write pages as they arrive instead of in page order")
+	searcher.cmd.PersistentFlags().Float64Var(&searcher.opts.rateLimit, "rate-limit", 0, "maximum API requests per second (0 for no limit)")
+	searcher.cmd.PersistentFlags().IntVar(&searcher.opts.burst, "burst", 1, "number of API requests allowed at once under the rate limit")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.recordDir, "record", "", "record API requests and responses to a cassette in this directory")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.replayDir, "replay", "", "replay API responses from a cassette in this directory instead of calling the API")
+	searcher.cmd.PersistentFlags().DurationVar(&searcher.opts.cacheTTL, "cache-ttl", 0, "cache API responses for this long (default is cache_ttl from the config file)")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.noCache, "no-cache", false, "don't read or write the response cache")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.checkpointFile, "checkpoint", "", "file to record search progress in after every page, with jsonl or template output")
+	searcher.cmd.PersistentFlags().StringVarP(&searcher.opts.format, "format", "f", string(output.FormatJSONL), "output format: jsonl, json, csv, tsv, table or template")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.template, "template", "", "Go template written for each certificate, e.g. '{{.Server}} {{join .Names \",\"}}' (implies --format template)")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.joiner, "joiner", output.DefaultJoiner, "separator for multi-valued fields in csv, tsv and table output")
+	searcher.cmd.PersistentFlags().StringSliceVar(&searcher.opts.fields, "fields", nil, "comma-separated fields to output, e.g. server,ssl_names,not_after")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.unique, "unique", "", "output each distinct value of this field once, one per line, e.g. ssl_names")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.uniqueDisk, "unique-disk", false, "track --unique values in a temporary file instead of memory, for large searches")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.groupBy, "group-by", "", "output the number of certificates per parent_domain, or per value of another field")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.privateSuffix, "private-suffixes", false, "treat private suffixes such as github.io as public when deriving parent domains")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.dedupe, "dedupe", false, "output each certificate only once, identified by fingerprint")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.dedupeServer, "dedupe-server", false, "identify certificates by fingerprint and server with --dedupe")
+	searcher.cmd.PersistentFlags().IntVar(&searcher.opts.dedupeSpill, "dedupe-spill", 1000000, "number of certificates tracked in memory by --dedupe before moving to a temporary file")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.resolve, "resolve", false, "resolve the names of the certificates found and write the findings instead of the certificates, like certsio resolve")
+	searcher.cmd.PersistentFlags().IntVar(&searcher.opts.resolveWorkers, "resolve-workers", 10, "number of names resolved in parallel with --resolve")
+	searcher.cmd.PersistentFlags().StringSliceVar(&searcher.opts.resolvers, "resolvers", nil, "comma-separated DNS servers to query with --resolve: host[:port], tls://host[:port] or https://host/path")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.resolversFile, "resolvers-file", "", "file of DNS servers to query with --resolve, one per line")
+	searcher.cmd.PersistentFlags().IntVar(&searcher.opts.dnsRetries, "dns-retries", 0, "number of attempts for each lookup with --resolve (default is dns_retries from the config file, or 1)")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.excludeExpired, "exclude-expired", false, "skip expired certificates")
+	searcher.cmd.PersistentFlags().BoolVar(&searcher.opts.selfSignedOnly, "self-signed-only", false, "only output self-signed certificates")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.issuedAfter, "issued-after", "", "only output certificates valid from after this date, e.g. 2024-01-01")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.expiresWithin, "expires-within", "", "only output certificates expiring within this duration, e.g. 30d")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.issuerOrg, "issuer-org", "", "only output certificates whose issuer organization contains this, ignoring case")
+	searcher.cmd.PersistentFlags().StringVar(&searcher.opts.nameRegex, "name-regex", "", "only output certificates with an ssl_name matching this regular expression")
+	searcher.cmd.Flags().StringVar(&searcher.opts.resumeFile, "resume", "", "resume a search from a checkpoint file")
 
 	// add additional subcommands
 	searcher.cmd.AddCommand(searcher.createSearchCommand(search.ByOrg))
@@ -38,6 +111,7 @@ func New(o *Options) *cobra.Command {
 	searcher.cmd.AddCommand(searcher.createSearchCommand(search.ByEmails))
 	searcher.cmd.AddCommand(searcher.createSearchCommand(search.ByCertNames))
 	searcher.cmd.AddCommand(searcher.createSearchCommand(search.ByServer))
+	searcher.cmd.AddCommand(searcher.createQueryCommand())
 
 	return searcher.cmd
 }
@@ -2,63 +2,247 @@ package searchcmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/certsio/certsio/pkg/config"
 
 	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/certresolve"
 	"github.com/certsio/certsio/pkg/output"
 	"github.com/certsio/certsio/pkg/search"
 	"github.com/spf13/cobra"
 )
 
+// streamFunc streams search results from the client to the result channel.
+type streamFunc func(ctx context.Context, client *search.Client, resultChan chan<- []certificate.Certificate) error
+
 // createSearchCommand creates a new search command for each searchable field.
 func (s *Search) createSearchCommand(field search.Field) *cobra.Command {
 	return &cobra.Command{
 		Use:   field.String(),
 		Short: fmt.Sprintf("Search for certificates by %s", field.String()),
 		Run: func(cmd *cobra.Command, args []string) {
+			if s.opts.inputFile != "" {
+				s.runBatch(loadConfig(cmd), field)
+				return
+			}
 			if len(args) == 0 {
 				fmt.Printf("usage: certsio search %s \"<value>\"\n", field.String())
+				fmt.Printf("       certsio search %s -i <file>\n", field.String())
 				os.Exit(1)
 			}
-			cfgFile, err := cmd.Root().Flags().GetString("config")
-			if err != nil {
-				log.Fatalf("couldn't get config file: %v", err)
+
+			s.runSearch(loadConfig(cmd), s.newCheckpoint(), func(ctx context.Context, client *search.Client, resultChan chan<- []certificate.Certificate) error {
+				return client.StreamSearchResults(ctx, &search.Query{
+					Field: field,
+					Value: args[0],
+					Page:  0,
+				}, resultChan)
+			})
+		},
+	}
+}
+
+// createQueryCommand creates the search command for boolean expressions across fields.
+func (s *Search) createQueryCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "query",
+		Short: "Search for certificates with a boolean expression across fields",
+		Long: `Search for certificates with a boolean expression across fields, e.g.
+
+  certsio search query 'org:"Uber" AND NOT ssl_names:*.internal'
+
+Each distinct field:value term is searched separately and the results are combined client-side.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				fmt.Println("usage: certsio search query \"<expression>\"")
+				os.Exit(1)
 			}
 
-			cfg, err := config.Get(cfgFile)
+			if s.opts.checkpointFile != "" {
+				log.Fatal("--checkpoint is not supported for query searches")
+			}
+
+			expr, err := search.ParseExpr(args[0])
 			if err != nil {
-				if err := config.Write(cfgFile); err != nil {
-					log.Fatalf("couldn't create config file: %v", err)
-				}
-				log.Fatalf("please update the config file with your API key")
+				log.Fatal(err)
 			}
 
-			s.runSearch(cfg, field, args)
+			s.runSearch(loadConfig(cmd), nil, func(ctx context.Context, client *search.Client, resultChan chan<- []certificate.Certificate) error {
+				certificates, err := client.SearchExpr(ctx, expr)
+				if err != nil {
+					return err
+				}
+				resultChan <- certificates
+				return nil
+			})
 		},
 	}
 }
 
+// runResume resumes the search recorded in the checkpoint given with --resume.
+func (s *Search) runResume(cmd *cobra.Command, args []string) {
+	if s.opts.resumeFile == "" {
+		_ = cmd.Help()
+		return
+	}
+
+	cp, err := search.LoadCheckpoint(s.opts.resumeFile)
+	if err != nil {
+		log.Fatalf("couldn't load checkpoint: %v", err)
+	}
+	if cp.Done() {
+		log.Printf("search for %s %q already completed all %d pages", cp.Query.Field, cp.Query.Value, cp.Pages)
+		return
+	}
+
+	// keep appending to the file the search was writing to.
+	if s.opts.OutputFile == "" {
+		s.opts.OutputFile = cp.OutputFile
+	}
+
+	s.runSearch(loadConfig(cmd), cp, func(ctx context.Context, client *search.Client, resultChan chan<- []certificate.Certificate) error {
+		return client.StreamSearchResults(ctx, cp.NextQuery(), resultChan)
+	})
+}
+
+// newCheckpoint returns the checkpoint requested with --checkpoint, or nil.
+func (s *Search) newCheckpoint() *search.Checkpoint {
+	if s.opts.checkpointFile == "" {
+		return nil
+	}
+
+	cp := search.NewCheckpoint(s.opts.checkpointFile)
+	if s.opts.OutputFile != "" {
+		outputFile, err := filepath.Abs(s.opts.OutputFile)
+		if err != nil {
+			log.Fatalf("couldn't resolve output file: %v", err)
+		}
+		cp.OutputFile = outputFile
+	}
+
+	return cp
+}
+
+// checkpointable returns an error unless the output options write every
+// certificate as soon as it is received, with no header, footer or state that
+// a resumed search would lose or write twice.
+func (s *Search) checkpointable() error {
+	format := output.Format(s.opts.format)
+	switch {
+	case s.opts.template == "" && format != output.FormatJSONL && format != output.FormatTemplate:
+		return fmt.Errorf("--checkpoint and --resume need the jsonl or template format, not %s", format)
+	case s.opts.groupBy != "":
+		return errors.New("--checkpoint and --resume can't be used with --group-by")
+	case s.opts.dedupe || s.opts.unique != "":
+		return errors.New("--checkpoint and --resume can't be used with --dedupe or --unique, the values seen aren't kept across runs")
+	case s.opts.resolve:
+		return errors.New("--checkpoint and --resume can't be used with --resolve")
+	default:
+		return nil
+	}
+}
+
+// loadConfig reads the config file given on the command line, creating it if it doesn't exist.
+func loadConfig(cmd *cobra.Command) config.Config {
+	cfgFile, err := cmd.Root().Flags().GetString("config")
+	if err != nil {
+		log.Fatalf("couldn't get config file: %v", err)
+	}
+
+	cfg, err := config.Get(cfgFile)
+	if err != nil {
+		if err := config.Write(cfgFile); err != nil {
+			log.Fatalf("couldn't create config file: %v", err)
+		}
+		log.Fatalf("please update the config file with your API key")
+	}
+
+	return cfg
+}
+
 // runSearch runs the search command.
-func (s *Search) runSearch(cfg config.Config, field search.Field, args []string) {
+func (s *Search) runSearch(cfg config.Config, cp *search.Checkpoint, stream streamFunc) {
 	var (
-		wg       sync.WaitGroup
-		writerWg sync.WaitGroup
-		file     *os.File
-		err      error
+		wg        sync.WaitGroup
+		writerWg  sync.WaitGroup
+		file      *os.File
+		err       error
+		quotaMu   sync.Mutex
+		quota     *search.QuotaState
+		streamErr error
 	)
 
 	// Create a new search client.
-	if cfg.APIKey == "" || cfg.APIKey == "CHANGE_ME" {
+	if s.opts.recordDir != "" && s.opts.replayDir != "" {
+		log.Fatalf("--record and --replay can't be used together")
+	}
+	if s.opts.resolve {
+		if err := s.resolvable(); err != nil {
+			log.Fatal(err)
+		}
+		if s.opts.resolveWorkers < 1 {
+			log.Fatalf("--resolve-workers must be at least 1")
+		}
+	}
+	if s.opts.replayDir == "" && (cfg.APIKey == "" || cfg.APIKey == "CHANGE_ME") {
 		log.Fatalf("Please update the config file with your API key")
 	}
 
 	client := search.NewClient(cfg)
-	client.WithMaxPages(s.opts.maxPages)
+	client.WithMaxPages(s.opts.maxPages).
+		WithConcurrency(s.opts.concurrency).
+		WithUnorderedResults(s.opts.unordered).
+		WithRateLimit(s.opts.rateLimit, s.opts.burst).
+		WithQuotaCallback(func(state search.QuotaState) {
+			quotaMu.Lock()
+			defer quotaMu.Unlock()
+			quota = &state
+		})
+	// the writer acknowledges each page once written, so checkpoints never
+	// get ahead of the output.
+	var acks chan struct{}
+	if cp != nil {
+		if err := s.checkpointable(); err != nil {
+			log.Fatal(err)
+		}
+		acks = make(chan struct{}, 1)
+		client.WithCheckpoint(cp).WithWriteAcks(acks)
+	}
+	if s.opts.cacheTTL > 0 {
+		cfg.CacheTTL = s.opts.cacheTTL
+	}
+	if !s.opts.noCache && cfg.CacheTTL > 0 {
+		cache, err := search.NewCacheFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("couldn't open cache: %v", err)
+		}
+		client.WithCache(cache)
+	}
+	if s.opts.recordDir != "" {
+		recorder, err := search.NewRecorder(s.opts.recordDir, nil)
+		if err != nil {
+			log.Fatalf("couldn't create cassette: %v", err)
+		}
+		defer recorder.Close()
+		client.WithHTTPTransport(recorder)
+	}
+	if s.opts.replayDir != "" {
+		replayer, err := search.NewReplayer(s.opts.replayDir)
+		if err != nil {
+			log.Fatalf("couldn't load cassette: %v", err)
+		}
+		client.WithHTTPTransport(replayer)
+	}
 
 	resultChan := make(chan []certificate.Certificate)
 
@@ -70,36 +254,165 @@ func (s *Search) runSearch(cfg config.Config, field search.Field, args []string)
 		}
 	}
 	defer file.Close()
-	writer := output.NewWriter(file)
+	keep, err := s.newFilter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// --resolve writes findings rather than certificates, so the certificate
+	// writer is only needed without it.
+	var writer output.CertificateWriter
+	if !s.opts.resolve {
+		if writer, err = s.newWriter(file); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// stop the search on Ctrl-C, letting the writer finish the results received so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resolveChan, resolved := s.startResolver(ctx, cfg, file)
 	writerWg.Add(1)
 	go func() {
 		defer writerWg.Done()
 		for results := range resultChan {
 			for _, res := range results {
+				if !keep(&res) {
+					continue
+				}
+				if resolveChan != nil {
+					resolveChan <- res
+					continue
+				}
 				if err := writer.Write(res); err != nil {
 					// TODO: handle error
 					continue
 				}
 			}
+			if acks != nil {
+				acks <- struct{}{}
+			}
 		}
 	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		err := client.StreamSearchResults(context.Background(), &search.Query{
-			Field: field,
-			Value: args[0],
-			Page:  0,
-		}, resultChan)
-		// check errors
-		if err != nil {
-			// TODO: handle error
-			return
-		}
+		streamErr = stream(ctx, client, resultChan)
 	}()
 
 	wg.Wait()
 	close(resultChan)
 	writerWg.Wait()
+	if resolveChan != nil {
+		close(resolveChan)
+		<-resolved
+	} else if err := writer.Flush(); err != nil {
+		log.Printf("couldn't write results: %v", err)
+	}
+
+	if quota != nil {
+		printQuota(*quota)
+	}
+
+	// results written before the failure are kept.
+	if streamErr != nil {
+		file.Close()
+		exitOnError(streamErr)
+	}
+}
+
+// startResolver starts resolving the names of the certificates sent to the
+// returned channel if --resolve was given, writing the findings to w. The
+// done channel is closed once the certificates channel is closed and every
+// name was resolved.
+func (s *Search) startResolver(ctx context.Context, cfg config.Config, w io.Writer) (chan<- certificate.Certificate, <-chan struct{}) {
+	if !s.opts.resolve {
+		return nil, nil
+	}
+
+	writer, err := certresolve.NewFindingWriter(w, output.Format(s.opts.format))
+	if err != nil {
+		log.Fatal(err)
+	}
+	resolverConfig := &certresolve.Config{
+		Writer:      writer,
+		WorkerCount: s.opts.resolveWorkers,
+	}
+	if err := resolverConfig.ConfigureResolvers(s.opts.resolvers, s.opts.resolversFile, s.opts.dnsRetries, cfg); err != nil {
+		log.Fatal(err)
+	}
+	resolver, err := certresolve.New(resolverConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	certs := make(chan certificate.Certificate)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resolver.Run(ctx, certs)
+	}()
+
+	return certs, done
+}
+
+// resolvable returns an error if output options that only apply to
+// certificates were given along with --resolve.
+func (s *Search) resolvable() error {
+	switch {
+	case s.opts.template != "":
+		return errors.New("--resolve can't be used with --template")
+	case len(s.opts.fields) > 0:
+		return errors.New("--resolve can't be used with --fields")
+	case s.opts.unique != "":
+		return errors.New("--resolve can't be used with --unique")
+	case s.opts.groupBy != "":
+		return errors.New("--resolve can't be used with --group-by")
+	case s.opts.dedupe:
+		return errors.New("--resolve can't be used with --dedupe")
+	default:
+		return nil
+	}
+}
+
+// newWriter creates the writer for the output format given on the command line.
+func (s *Search) newWriter(w io.Writer) (output.CertificateWriter, error) {
+	format := output.Format(s.opts.format)
+	if s.opts.template != "" {
+		format = output.FormatTemplate
+	}
+	opts := output.Options{
+		Format:   format,
+		Joiner:   s.opts.joiner,
+		Template: s.opts.template,
+		Fields:   s.opts.fields,
+		Unique:   s.opts.unique,
+		GroupBy:  s.opts.groupBy,
+		Domains:  certificate.DomainOptions{PrivateSuffixes: s.opts.privateSuffix},
+	}
+	if s.opts.unique != "" && s.opts.uniqueDisk {
+		seen, err := output.NewDiskSet("")
+		if err != nil {
+			return nil, err
+		}
+		opts.Seen = seen
+	}
+	writer, err := output.New(w, opts)
+	if err != nil || !s.opts.dedupe {
+		return writer, err
+	}
+	return output.NewDedupeWriter(writer, output.NewSpillSet(s.opts.dedupeSpill, ""), s.opts.dedupeServer), nil
+}
+
+// printQuota reports the remaining API credits on stderr.
+func printQuota(quota search.QuotaState) {
+	remaining := fmt.Sprintf("%d", quota.Remaining)
+	if quota.Limit >= 0 {
+		remaining = fmt.Sprintf("%d/%d", quota.Remaining, quota.Limit)
+	}
+	if quota.ResetAt.IsZero() {
+		log.Printf("remaining API requests: %s", remaining)
+		return
+	}
+	log.Printf("remaining API requests: %s (resets in %s)", remaining, time.Until(quota.ResetAt).Round(time.Second))
 }
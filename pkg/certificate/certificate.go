@@ -36,6 +36,8 @@ type Certificate struct {
 	FingerprintSha256Hash string `json:"fingerprint_sha256"`
 	// ParentDomains is the list of parent domains for ssl_names in the certificate
 	ParentDomains []string `json:"parent_domains"`
+	// QueryTerm is the search term that returned the certificate in a batch search
+	QueryTerm string `json:"query_term,omitempty"`
 }
 
 // Unmarshal parses a certificate from JSON-encoded data.
@@ -0,0 +1,70 @@
+package certificate
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/weppos/publicsuffix-go/publicsuffix"
+)
+
+// DomainOptions controls how registrable domains are derived from names.
+type DomainOptions struct {
+	// PrivateSuffixes treats the private section of the Public Suffix List,
+	// e.g. github.io, as public suffixes, so foo.github.io is registrable.
+	PrivateSuffixes bool
+	// Unicode returns internationalized domains in Unicode instead of punycode.
+	Unicode bool
+}
+
+// RegistrableDomain returns the registrable domain of name, the public suffix
+// plus one label, according to the Public Suffix List. A leading wildcard
+// label is ignored and internationalized names are normalized to punycode.
+// IP addresses and public suffixes have no registrable domain.
+func RegistrableDomain(name string, opts DomainOptions) (string, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	name = strings.TrimPrefix(name, "*.")
+	if name == "" || net.ParseIP(name) != nil {
+		return "", fmt.Errorf("certificate: %q is not a domain name", name)
+	}
+
+	ascii, err := publicsuffix.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("certificate: %w", err)
+	}
+
+	domain, err := publicsuffix.DomainFromListWithOptions(publicsuffix.DefaultList, ascii, &publicsuffix.FindOptions{
+		IgnorePrivate: !opts.PrivateSuffixes,
+		DefaultRule:   publicsuffix.DefaultRule,
+	})
+	if err != nil {
+		return "", fmt.Errorf("certificate: %w", err)
+	}
+
+	if opts.Unicode {
+		if domain, err = publicsuffix.ToUnicode(domain); err != nil {
+			return "", fmt.Errorf("certificate: %w", err)
+		}
+	}
+	return domain, nil
+}
+
+// RegistrableDomains returns the distinct registrable domains of the
+// certificate names, skipping names without one.
+func (c *Certificate) RegistrableDomains(opts DomainOptions) []string {
+	domains := make([]string, 0, len(c.Names))
+	for _, name := range c.Names {
+		if domain, err := RegistrableDomain(name, opts); err == nil {
+			domains = append(domains, domain)
+		}
+	}
+	return unique(domains)
+}
+
+// FillParentDomains sets ParentDomains from the certificate names if the
+// source didn't provide them.
+func (c *Certificate) FillParentDomains(opts DomainOptions) {
+	if len(c.ParentDomains) == 0 {
+		c.ParentDomains = c.RegistrableDomains(opts)
+	}
+}
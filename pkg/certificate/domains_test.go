@@ -0,0 +1,56 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DomainsTestSuite struct {
+	suite.Suite
+}
+
+// TestRegistrableDomain tests deriving registrable domains from names.
+func (s *DomainsTestSuite) TestRegistrableDomain() {
+	tests := []struct {
+		name string
+		opts DomainOptions
+		want string
+	}{
+		{"www.example.com", DomainOptions{}, "example.com"},
+		{"*.api.example.co.uk", DomainOptions{}, "example.co.uk"},
+		{"WWW.Example.COM.", DomainOptions{}, "example.com"},
+		{"foo.bar.github.io", DomainOptions{}, "github.io"},
+		{"foo.bar.github.io", DomainOptions{PrivateSuffixes: true}, "bar.github.io"},
+		{"www.bücher.de", DomainOptions{}, "xn--bcher-kva.de"},
+		{"www.bücher.de", DomainOptions{Unicode: true}, "bücher.de"},
+		{"db.internal", DomainOptions{}, "db.internal"},
+	}
+	for _, tt := range tests {
+		got, err := RegistrableDomain(tt.name, tt.opts)
+		s.Require().NoError(err, tt.name)
+		s.Equal(tt.want, got, tt.name)
+	}
+
+	for _, name := range []string{"co.uk", "*.co.uk", "10.0.0.1", ""} {
+		_, err := RegistrableDomain(name, DomainOptions{})
+		s.Error(err, name)
+	}
+}
+
+// TestFillParentDomains tests filling ParentDomains from Names.
+func (s *DomainsTestSuite) TestFillParentDomains() {
+	cert := Certificate{Names: []string{"example.com", "*.example.com", "www.example.org", "10.0.0.1"}}
+	cert.FillParentDomains(DomainOptions{})
+	s.Equal([]string{"example.com", "example.org"}, cert.ParentDomains)
+
+	// domains from the API are kept.
+	cert = Certificate{Names: []string{"example.com"}, ParentDomains: []string{"api.example"}}
+	cert.FillParentDomains(DomainOptions{})
+	s.Equal([]string{"api.example"}, cert.ParentDomains)
+}
+
+// TestRunDomainsTestSuite runs the test suite.
+func TestRunDomainsTestSuite(t *testing.T) {
+	suite.Run(t, new(DomainsTestSuite))
+}
@@ -0,0 +1,147 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// FromX509 converts a parsed certificate seen on server into a Certificate.
+// Timestamp is left empty and ParentDomains is derived from the names.
+func FromX509(cert *x509.Certificate, server string) Certificate {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	c := Certificate{
+		Server:                server,
+		Expired:               time.Now().After(cert.NotAfter),
+		SelfSigned:            isSelfSigned(cert),
+		NotBefore:             cert.NotBefore.UTC(),
+		NotAfter:              cert.NotAfter.UTC(),
+		Names:                 unique(append([]string{cert.Subject.CommonName}, cert.DNSNames...)),
+		SubjectOrg:            cert.Subject.Organization,
+		Serial:                formatSerial(cert.SerialNumber.Bytes()),
+		IssuerNames:           unique([]string{cert.Issuer.CommonName}),
+		IssuerOrg:             cert.Issuer.Organization,
+		Emails:                unique(append(subjectEmails(cert), cert.EmailAddresses...)),
+		FingerprintSha256Hash: hex.EncodeToString(fingerprint[:]),
+	}
+	c.FillParentDomains(DomainOptions{})
+
+	return c
+}
+
+// ReadPEM parses every certificate in PEM-encoded data, skipping other blocks.
+func ReadPEM(r io.Reader) ([]*x509.Certificate, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("certificate: no PEM certificates found")
+	}
+
+	return certs, nil
+}
+
+// ReadDER parses one or more concatenated DER-encoded certificates.
+func ReadDER(r io.Reader) ([]*x509.Certificate, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+	certs, err := x509.ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("certificate: no DER certificates found")
+	}
+	return certs, nil
+}
+
+// ReadFile parses the certificates in a PEM or DER file.
+func ReadFile(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return ReadPEM(bytes.NewReader(data))
+	}
+	return ReadDER(bytes.NewReader(data))
+}
+
+// isSelfSigned reports whether cert is signed by its own key.
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+		return false
+	}
+	return cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// formatSerial formats a serial number as colon-separated uppercase hex bytes, as the API does.
+func formatSerial(serial []byte) string {
+	if len(serial) == 0 {
+		serial = []byte{0}
+	}
+	parts := make([]string, len(serial))
+	for i, b := range serial {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// subjectEmails returns the emailAddress attributes of the certificate subject.
+func subjectEmails(cert *x509.Certificate) []string {
+	var emails []string
+	for _, name := range cert.Subject.Names {
+		if name.Type.Equal(oidEmailAddress) {
+			if email, ok := name.Value.(string); ok {
+				emails = append(emails, email)
+			}
+		}
+	}
+	return emails
+}
+
+// oidEmailAddress is the PKCS #9 emailAddress attribute.
+var oidEmailAddress = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+
+// unique returns the non-empty values, without repeats, in their original order.
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
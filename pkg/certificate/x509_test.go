@@ -0,0 +1,72 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type X509TestSuite struct {
+	suite.Suite
+}
+
+// TestFromX509 tests converting a self-signed certificate read from PEM and DER.
+func (s *X509TestSuite) TestFromX509() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	notAfter := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(0x0c1fcb),
+		Subject:        pkix.Name{CommonName: "example.com", Organization: []string{"Example Inc"}},
+		DNSNames:       []string{"example.com", "www.example.com"},
+		EmailAddresses: []string{"admin@example.com"},
+		NotBefore:      notAfter.Add(-24 * time.Hour),
+		NotAfter:       notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+
+	var pemData bytes.Buffer
+	s.Require().NoError(pem.Encode(&pemData, &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("skipped")}))
+	s.Require().NoError(pem.Encode(&pemData, &pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	fromPEM, err := ReadPEM(&pemData)
+	s.Require().NoError(err)
+	fromDER, err := ReadDER(bytes.NewReader(der))
+	s.Require().NoError(err)
+	s.Require().Len(fromPEM, 1)
+	s.Require().Len(fromDER, 1)
+	s.Equal(fromPEM[0].Raw, fromDER[0].Raw)
+
+	cert := FromX509(fromPEM[0], "10.0.0.1:443")
+	s.Equal("10.0.0.1:443", cert.Server)
+	s.Equal([]string{"example.com", "www.example.com"}, cert.Names)
+	s.Equal([]string{"Example Inc"}, cert.SubjectOrg)
+	s.Equal("0C:1F:CB", cert.Serial)
+	s.Equal([]string{"example.com"}, cert.IssuerNames)
+	s.Equal([]string{"admin@example.com"}, cert.Emails)
+	s.Len(cert.FingerprintSha256Hash, 64)
+	s.Equal(notAfter, cert.NotAfter)
+	s.True(cert.SelfSigned)
+	s.True(cert.Expired)
+
+	_, err = ReadPEM(bytes.NewReader([]byte("not a certificate")))
+	s.Error(err)
+	_, err = ReadDER(bytes.NewReader(nil))
+	s.Error(err)
+}
+
+// TestRunX509TestSuite runs the test suite.
+func TestRunX509TestSuite(t *testing.T) {
+	suite.Run(t, new(X509TestSuite))
+}
@@ -2,63 +2,143 @@ package certresolve
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"net"
+	"os"
 	"strings"
 	"sync"
 
 	"github.com/certsio/certsio/internal/resolver"
 	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/certsio/certsio/pkg/output"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/sirupsen/logrus"
 )
 
 // Config defines the certificate resolver configuration.
 type Config struct {
+	// Writer receives the findings, JSON lines on stdout if nil.
+	Writer FindingWriter
+	// Classifier classifies resolved and server IP addresses, NewClassifier if nil.
+	Classifier Classifier
+	// WorkerCount is the number of names resolved at once.
 	WorkerCount int
+	// Resolvers are the DNS servers to query as host:port, the default resolvers if empty.
+	Resolvers []string
+	// Retries is the number of attempts for each lookup.
+	Retries int
+	// Verifier confirms findings against the server the certificate was seen on, if set.
+	Verifier *Verifier
+}
+
+// ConfigureResolvers sets the DNS servers and retries of c from the command
+// line and cfg. DNS servers given in specs or read from file replace those
+// in cfg, and retries replaces cfg.DNSRetries if positive.
+func (c *Config) ConfigureResolvers(specs []string, file string, retries int, cfg config.Config) error {
+	if len(specs) == 0 && file == "" {
+		specs, file = cfg.Resolvers, cfg.ResolversFile
+	}
+	if file != "" {
+		fromFile, err := resolver.LoadUpstreams(file)
+		if err != nil {
+			return fmt.Errorf("certresolve: %w", err)
+		}
+		specs = append(specs, fromFile...)
+	}
+	c.Resolvers = specs
+
+	c.Retries = cfg.DNSRetries
+	if retries > 0 {
+		c.Retries = retries
+	}
+	return nil
 }
 
 // Resolver drives the resolution of certificate names.
 type Resolver struct {
 	config *Config
 	pool   *resolver.Pool
+	// mu serializes writes to the finding writer.
+	mu sync.Mutex
 }
 
 // New creates a new certificate resolver.
 func New(c *Config) (*Resolver, error) {
-	client, err := resolver.New()
+	client, err := resolver.New(resolver.Options{Resolvers: c.Resolvers, Retries: c.Retries})
 	if err != nil {
 		return nil, fmt.Errorf("certresolve: %w", err)
 	}
 
+	if c.Writer == nil {
+		c.Writer, _ = NewFindingWriter(os.Stdout, output.FormatJSONL)
+	}
+	if c.Classifier == nil {
+		c.Classifier = NewClassifier()
+	}
+
 	return &Resolver{
 		config: c,
 		pool:   client.NewPool(c.WorkerCount),
 	}, nil
 }
 
-func (r *Resolver) Start(in io.Reader) {
-	// process the output
+// Start resolves the names of the certificates read from in, in certsio JSONL
+// format. Cancelling ctx stops verifying findings.
+func (r *Resolver) Start(ctx context.Context, in io.Reader) {
+	certs := make(chan certificate.Certificate)
+	go func() {
+		defer close(certs)
+		reader := bufio.NewScanner(in)
+		reader.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for reader.Scan() {
+			var cert certificate.Certificate
+			if err := jsoniter.Unmarshal(reader.Bytes(), &cert); err != nil {
+				continue
+			}
+			certs <- cert
+		}
+	}()
+
+	r.Run(ctx, certs)
+}
+
+// Run resolves the names of the certificates received until certs is closed.
+// A Resolver can only be run once. Cancelling ctx stops verifying findings.
+func (r *Resolver) Run(ctx context.Context, certs <-chan certificate.Certificate) {
+	// process the output, verifying findings can take as long as resolving.
 	var outWg sync.WaitGroup
 	outWg.Add(1)
 	go func() {
 		defer outWg.Done()
+		var (
+			wg      sync.WaitGroup
+			workers = make(chan struct{}, max(r.config.WorkerCount, 1))
+		)
 		for result := range r.pool.Results {
-			r.processResult(result)
+			workers <- struct{}{}
+			wg.Add(1)
+			go func(result resolver.Result) {
+				defer wg.Done()
+				defer func() { <-workers }()
+				r.processResult(ctx, result)
+			}(result)
 		}
+		wg.Wait()
 	}()
 
-	reader := bufio.NewScanner(in)
-	for reader.Scan() {
-		var cert certificate.Certificate
-		if err := jsoniter.Unmarshal(reader.Bytes(), &cert); err != nil {
-			continue
-		}
+	for cert := range certs {
 		r.resolveCertificateNames(cert)
 	}
 
 	close(r.pool.Tasks)
 	outWg.Wait()
+
+	if err := r.config.Writer.Flush(); err != nil {
+		logrus.WithError(err).Error("couldn't write findings")
+	}
 }
 
 // resolveCertificateNames resolves the names of a certificate.
@@ -68,28 +148,117 @@ func (r *Resolver) resolveCertificateNames(cert certificate.Certificate) {
 	}
 }
 
-func (r *Resolver) processResult(result resolver.Result) {
+// processResult writes the finding for a resolution result, if any.
+func (r *Resolver) processResult(ctx context.Context, result resolver.Result) {
+	finding := Finding{
+		Host:        result.Task.Host,
+		ResolvedIPs: append(append([]string{}, result.IPs...), result.IPv6...),
+		CNAMEs:      result.CNAMEs,
+		TTL:         addressTTL(result.Records),
+		Resolver:    result.Resolver,
+		Server:      result.Task.Source.Server,
+		Fingerprint: result.Task.Source.FingerprintSha256Hash,
+	}
+
 	switch result.Type {
 	case resolver.Alive:
-		var skip bool
-		certIP := strings.Split(result.Task.Source.Server, ":")[0]
-		// if certIP not in result.IPs, then print
-		for _, ip := range result.IPs {
-			if ip == certIP {
-				skip = true
+		// a public name pointing into a private network leaks its layout.
+		for _, ip := range finding.ResolvedIPs {
+			if isInternalIP(net.ParseIP(ip)) {
+				finding.Kind = KindInternalIPLeak
+				break
 			}
 		}
-		if !skip {
-			fields := logrus.Fields{}
-			fields["host"] = result.Task.Host
-			fields["resolved_ips"] = result.IPs
-			fields["source_ip"] = certIP
-			logrus.WithFields(fields).Info("Possible Origin Bypass")
+		if finding.Kind != "" {
+			break
 		}
+
+		certIP := serverIP(result.Task.Source.Server)
+		if certIP == nil {
+			return
+		}
+
+		// the name points at the server the certificate was seen on.
+		for _, ip := range finding.ResolvedIPs {
+			if certIP.Equal(net.ParseIP(ip)) {
+				return
+			}
+		}
+
+		// a bypass needs the name fronted by a CDN or WAF while the certificate
+		// was served from outside one.
+		server := r.config.Classifier.Classify(certIP)
+		var fronted bool
+		for _, ip := range finding.ResolvedIPs {
+			class := r.config.Classifier.Classify(net.ParseIP(ip))
+			finding.Addresses = append(finding.Addresses, class)
+			if class.Class.Fronting() {
+				fronted = true
+				finding.Providers = appendUnique(finding.Providers, class.Provider)
+			}
+		}
+		if !fronted || server.Class.Fronting() {
+			logrus.WithField("host", result.Task.Host).WithField("server", result.Task.Source.Server).Debug("name resolves elsewhere, not fronted by a CDN")
+			return
+		}
+		finding.Kind = KindOriginBypass
+		finding.ServerClass = server.Class
+		finding.ServerProvider = server.Provider
+	case resolver.NXDomain:
+		finding.Kind = KindInternalHost
+		finding.DNSError = result.Type.String()
 	default:
-		fields := logrus.Fields{}
-		fields["host"] = result.Task.Host
-		fields["source"] = result.Task.Source.Server
-		logrus.WithFields(fields).Warn("Possible Internal Host")
+		// failures other than NXDOMAIN say nothing about where the name lives.
+		logrus.WithError(result.Error).WithField("host", result.Task.Host).WithField("class", result.Type.String()).Debug("lookup failed")
+		return
+	}
+
+	if r.config.Verifier != nil && serverIP(finding.Server) != nil {
+		r.config.Verifier.Verify(ctx, &finding)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.config.Writer.Write(finding); err != nil {
+		logrus.WithError(err).Error("couldn't write finding")
+	}
+}
+
+// serverIP returns the IP address of a certificate server, an IP address
+// with an optional port.
+func serverIP(server string) net.IP {
+	if host, _, err := net.SplitHostPort(server); err == nil {
+		server = host
+	}
+	return net.ParseIP(strings.Trim(server, "[]"))
+}
+
+// appendUnique appends value to values unless it is empty or already present.
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// isInternalIP reports whether ip is an RFC 1918 or RFC 4193 private,
+// loopback or link-local address.
+func isInternalIP(ip net.IP) bool {
+	return ip != nil && (ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast())
+}
+
+// addressTTL returns the lowest TTL of the A and AAAA records.
+func addressTTL(records []resolver.Record) uint32 {
+	var ttl uint32
+	for _, record := range records {
+		if (record.Type == "A" || record.Type == "AAAA") && (ttl == 0 || record.TTL < ttl) {
+			ttl = record.TTL
+		}
 	}
+	return ttl
 }
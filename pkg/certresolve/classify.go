@@ -0,0 +1,73 @@
+package certresolve
+
+import (
+	"net"
+
+	"github.com/projectdiscovery/cdncheck"
+)
+
+// Class is the kind of network an IP address belongs to.
+type Class string
+
+const (
+	// ClassCDN is a content delivery network.
+	ClassCDN Class = "cdn"
+	// ClassWAF is a web application firewall service.
+	ClassWAF Class = "waf"
+	// ClassCloud is a cloud provider.
+	ClassCloud Class = "cloud"
+	// ClassUnknown is any other network.
+	ClassUnknown Class = "unknown"
+)
+
+// Fronting reports whether the class is a CDN or WAF in front of an origin.
+func (c Class) Fronting() bool {
+	return c == ClassCDN || c == ClassWAF
+}
+
+// IPClass is the classification of an IP address.
+type IPClass struct {
+	// IP is the classified address.
+	IP string `json:"ip"`
+	// Class is the kind of network the address belongs to.
+	Class Class `json:"class"`
+	// Provider is the CDN, WAF or cloud provider, if known.
+	Provider string `json:"provider,omitempty"`
+}
+
+// Classifier classifies IP addresses.
+type Classifier interface {
+	Classify(ip net.IP) IPClass
+}
+
+// cdnClassifier classifies IP addresses with the cdncheck provider ranges.
+type cdnClassifier struct {
+	client *cdncheck.Client
+}
+
+// NewClassifier creates a classifier using the CDN, WAF and cloud provider
+// ranges shipped with cdncheck. It makes no network requests.
+func NewClassifier() Classifier {
+	return &cdnClassifier{client: cdncheck.New()}
+}
+
+// Classify returns the class and provider of ip.
+func (c *cdnClassifier) Classify(ip net.IP) IPClass {
+	class := IPClass{IP: ip.String(), Class: ClassUnknown}
+
+	matched, provider, itemType, err := c.client.Check(ip)
+	if err != nil || !matched {
+		return class
+	}
+	switch itemType {
+	case "cdn":
+		class.Class = ClassCDN
+	case "waf":
+		class.Class = ClassWAF
+	case "cloud":
+		class.Class = ClassCloud
+	}
+	class.Provider = provider
+
+	return class
+}
@@ -0,0 +1,208 @@
+package certresolve
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/certsio/certsio/pkg/output"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Kind is the kind of a finding.
+type Kind string
+
+const (
+	// KindOriginBypass is a name fronted by a CDN or WAF whose certificate was
+	// seen on a server outside one, which may be the origin reachable directly.
+	KindOriginBypass Kind = "possible_origin_bypass"
+	// KindInternalHost is a name that doesn't exist in public DNS.
+	KindInternalHost Kind = "possible_internal_host"
+	// KindInternalIPLeak is a name resolving publicly to a private, loopback
+	// or link-local address.
+	KindInternalIPLeak Kind = "internal_ip_leak"
+	// KindConfirmedOriginBypass is an origin bypass whose server presented the
+	// certificate for the host.
+	KindConfirmedOriginBypass Kind = "confirmed_origin_bypass"
+	// KindConfirmedInternalHost is an internal host whose server presented the
+	// certificate for the host.
+	KindConfirmedInternalHost Kind = "confirmed_internal_host"
+)
+
+// confirmed returns the confirmed kind of a possible finding.
+func (k Kind) confirmed() Kind {
+	switch k {
+	case KindOriginBypass:
+		return KindConfirmedOriginBypass
+	case KindInternalHost:
+		return KindConfirmedInternalHost
+	default:
+		return k
+	}
+}
+
+// Finding is something of interest found by resolving a certificate name.
+type Finding struct {
+	// Kind is the kind of finding.
+	Kind Kind `json:"kind"`
+	// Host is the certificate name that was resolved.
+	Host string `json:"host"`
+	// ResolvedIPs are the IPv4 and IPv6 addresses the host resolved to.
+	ResolvedIPs []string `json:"resolved_ips"`
+	// CNAMEs is the CNAME chain followed from the host.
+	CNAMEs []string `json:"cnames,omitempty"`
+	// TTL is the lowest TTL of the address records, in seconds.
+	TTL uint32 `json:"ttl,omitempty"`
+	// Resolver is the DNS server that answered.
+	Resolver string `json:"resolver,omitempty"`
+	// Addresses classifies each resolved address.
+	Addresses []IPClass `json:"addresses,omitempty"`
+	// Providers are the CDN and WAF providers fronting the host.
+	Providers []string `json:"providers,omitempty"`
+	// Server is the server the certificate was seen on.
+	Server string `json:"server"`
+	// ServerClass is the kind of network the server belongs to.
+	ServerClass Class `json:"server_class,omitempty"`
+	// ServerProvider is the cloud provider of the server, if known.
+	ServerProvider string `json:"server_provider,omitempty"`
+	// Fingerprint is the SHA-256 fingerprint of the certificate.
+	Fingerprint string `json:"fingerprint_sha256"`
+	// DNSError is the class of DNS failure for hosts that didn't resolve, e.g. nxdomain.
+	DNSError string `json:"dns_error,omitempty"`
+	// Evidence is the outcome of verifying the finding, if verified.
+	Evidence *Evidence `json:"evidence,omitempty"`
+}
+
+// findingColumns are the finding fields in flattened output.
+var findingColumns = []string{"kind", "host", "resolved_ips", "cnames", "providers", "server", "server_class", "server_provider", "fingerprint_sha256", "dns_error", "presented_sha256", "http_match"}
+
+// row returns the finding fields in findingColumns order.
+func (f *Finding) row() []string {
+	var presented, httpMatch string
+	if f.Evidence != nil {
+		presented = f.Evidence.Fingerprint
+		if f.Evidence.HTTP != nil && f.Evidence.HTTP.Fronted != nil {
+			httpMatch = strconv.FormatBool(f.Evidence.HTTP.Match)
+		}
+	}
+
+	return []string{
+		string(f.Kind),
+		f.Host,
+		strings.Join(f.ResolvedIPs, output.DefaultJoiner),
+		strings.Join(f.CNAMEs, output.DefaultJoiner),
+		strings.Join(f.Providers, output.DefaultJoiner),
+		f.Server,
+		string(f.ServerClass),
+		f.ServerProvider,
+		f.Fingerprint,
+		f.DNSError,
+		presented,
+		httpMatch,
+	}
+}
+
+// FindingWriter writes findings to a destination.
+type FindingWriter interface {
+	Write(finding Finding) error
+	// Flush writes any buffered output. It must be called once all findings were written.
+	Flush() error
+}
+
+// NewFindingWriter creates a writer for findings in the given format, which
+// is one of output.FormatJSONL, output.FormatCSV, output.FormatTSV or
+// output.FormatTable.
+func NewFindingWriter(w io.Writer, format output.Format) (FindingWriter, error) {
+	switch format {
+	case "", output.FormatJSONL:
+		return &jsonlFindingWriter{writer: w}, nil
+	case output.FormatCSV:
+		return newDelimitedFindingWriter(w, ','), nil
+	case output.FormatTSV:
+		return newDelimitedFindingWriter(w, '\t'), nil
+	case output.FormatTable:
+		return &tableFindingWriter{writer: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}, nil
+	default:
+		return nil, fmt.Errorf("certresolve: unsupported findings format %q", format)
+	}
+}
+
+// jsonlFindingWriter writes findings as JSON lines.
+type jsonlFindingWriter struct {
+	writer io.Writer
+}
+
+// Write writes the finding as a JSON line.
+func (w *jsonlFindingWriter) Write(finding Finding) error {
+	return jsoniter.NewEncoder(w.writer).Encode(&finding)
+}
+
+// Flush is a no-op, every finding is written immediately.
+func (w *jsonlFindingWriter) Flush() error {
+	return nil
+}
+
+// delimitedFindingWriter writes findings as delimiter-separated values with a header row.
+type delimitedFindingWriter struct {
+	writer *csv.Writer
+}
+
+func newDelimitedFindingWriter(w io.Writer, comma rune) *delimitedFindingWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	_ = writer.Write(findingColumns)
+	return &delimitedFindingWriter{writer: writer}
+}
+
+// Write writes the finding as a row.
+func (w *delimitedFindingWriter) Write(finding Finding) error {
+	if err := w.writer.Write(finding.row()); err != nil {
+		return err
+	}
+	// findings trickle in while names resolve, don't hold them back.
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Flush writes the buffered rows.
+func (w *delimitedFindingWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// tableFindingWriter writes findings as an aligned table on Flush.
+type tableFindingWriter struct {
+	writer        *tabwriter.Writer
+	headerWritten bool
+}
+
+// Write adds the finding as a row.
+func (w *tableFindingWriter) Write(finding Finding) error {
+	if !w.headerWritten {
+		w.writeHeader()
+	}
+	row := finding.row()
+	for i, value := range row {
+		if value == "" {
+			row[i] = "-"
+		}
+	}
+	_, err := io.WriteString(w.writer, strings.Join(row, "\t")+"\n")
+	return err
+}
+
+// Flush writes the aligned table.
+func (w *tableFindingWriter) Flush() error {
+	if !w.headerWritten {
+		w.writeHeader()
+	}
+	return w.writer.Flush()
+}
+
+func (w *tableFindingWriter) writeHeader() {
+	w.headerWritten = true
+	_, _ = io.WriteString(w.writer, strings.ToUpper(strings.Join(findingColumns, "\t"))+"\n")
+}
@@ -0,0 +1,91 @@
+package certresolve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/certsio/certsio/internal/resolver"
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/output"
+	"github.com/stretchr/testify/suite"
+)
+
+type FindingTestSuite struct {
+	suite.Suite
+	classifier Classifier
+}
+
+// SetupSuite loads the provider ranges once.
+func (s *FindingTestSuite) SetupSuite() {
+	s.classifier = NewClassifier()
+}
+
+// process returns the findings written for results in the given format.
+func (s *FindingTestSuite) process(format output.Format, results ...resolver.Result) string {
+	var buf bytes.Buffer
+	writer, err := NewFindingWriter(&buf, format)
+	s.Require().NoError(err)
+
+	r := &Resolver{config: &Config{Writer: writer, Classifier: s.classifier}}
+	for _, result := range results {
+		r.processResult(context.Background(), result)
+	}
+	s.Require().NoError(writer.Flush())
+	return buf.String()
+}
+
+// TestProcessResult tests the findings written for resolution results.
+func (s *FindingTestSuite) TestProcessResult() {
+	// the certificate was seen on an AWS address.
+	source := certificate.Certificate{Server: "3.5.140.1:443", FingerprintSha256Hash: "aa"}
+	results := []resolver.Result{
+		// resolves to the server the certificate was seen on, nothing to report.
+		{Type: resolver.Alive, Task: resolver.HostEntry{Host: "www.example.com", Source: source}, IPs: []string{"3.5.140.1"}},
+		// resolves over IPv6 to the server the certificate was seen on.
+		{Type: resolver.Alive, Task: resolver.HostEntry{Host: "v6.example.com", Source: certificate.Certificate{Server: "[2001:db8::1]:443"}}, IPv6: []string{"2001:db8::1"}},
+		// resolves elsewhere but isn't fronted by a CDN or WAF.
+		{Type: resolver.Alive, Task: resolver.HostEntry{Host: "other.example.com", Source: source}, IPs: []string{"192.0.2.1"}},
+		// fronted by Fastly, but the certificate was seen on Fastly too.
+		{Type: resolver.Alive, Task: resolver.HostEntry{Host: "edge.example.com", Source: certificate.Certificate{Server: "23.235.32.2:443"}}, IPs: []string{"23.235.32.1"}},
+		// fronted by Cloudflare, the certificate was seen on AWS.
+		{
+			Type:     resolver.Alive,
+			Task:     resolver.HostEntry{Host: "cdn.example.com", Source: source},
+			IPs:      []string{"173.245.48.1"},
+			IPv6:     []string{"2001:db8::2"},
+			CNAMEs:   []string{"example.cdn.test"},
+			Records:  []resolver.Record{{Name: "cdn.example.com", Type: "CNAME", Value: "example.cdn.test", TTL: 300}, {Name: "example.cdn.test", Type: "A", Value: "173.245.48.1", TTL: 60}},
+			Resolver: "127.0.0.1:53",
+		},
+		// resolves publicly to a private address.
+		{Type: resolver.Alive, Task: resolver.HostEntry{Host: "vpn.example.com", Source: source}, IPs: []string{"203.0.113.1", "10.1.2.3"}},
+		{Type: resolver.NXDomain, Task: resolver.HostEntry{Host: "db.internal", Source: source}, Error: errors.New("resolver: db.internal: NXDOMAIN")},
+		// other failures say nothing about the name.
+		{Type: resolver.NoData, Task: resolver.HostEntry{Host: "mail.example.com", Source: source}, Error: errors.New("no ips found")},
+		{Type: resolver.ServFail, Task: resolver.HostEntry{Host: "broken.example.com", Source: source}, Error: errors.New("resolver: broken.example.com: SERVFAIL")},
+		{Type: resolver.Timeout, Task: resolver.HostEntry{Host: "slow.example.com", Source: source}, Error: errors.New("i/o timeout")},
+	}
+
+	s.Equal(`{"kind":"possible_origin_bypass","host":"cdn.example.com","resolved_ips":["173.245.48.1","2001:db8::2"],"cnames":["example.cdn.test"],"ttl":60,"resolver":"127.0.0.1:53","addresses":[{"ip":"173.245.48.1","class":"waf","provider":"cloudflare"},{"ip":"2001:db8::2","class":"unknown"}],"providers":["cloudflare"],"server":"3.5.140.1:443","server_class":"cloud","server_provider":"aws","fingerprint_sha256":"aa"}
+{"kind":"internal_ip_leak","host":"vpn.example.com","resolved_ips":["203.0.113.1","10.1.2.3"],"server":"3.5.140.1:443","fingerprint_sha256":"aa"}
+{"kind":"possible_internal_host","host":"db.internal","resolved_ips":[],"server":"3.5.140.1:443","fingerprint_sha256":"aa","dns_error":"nxdomain"}
+`, s.process(output.FormatJSONL, results...))
+
+	s.Equal(`kind,host,resolved_ips,cnames,providers,server,server_class,server_provider,fingerprint_sha256,dns_error,presented_sha256,http_match
+possible_origin_bypass,cdn.example.com,173.245.48.1;2001:db8::2,example.cdn.test,cloudflare,3.5.140.1:443,cloud,aws,aa,,,
+internal_ip_leak,vpn.example.com,203.0.113.1;10.1.2.3,,,3.5.140.1:443,,,aa,,,
+possible_internal_host,db.internal,,,,3.5.140.1:443,,,aa,nxdomain,,
+`, s.process(output.FormatCSV, results...))
+
+	s.Contains(s.process(output.FormatTable, results...), "possible_internal_host  db.internal      -")
+
+	_, err := NewFindingWriter(&bytes.Buffer{}, output.FormatTemplate)
+	s.Error(err)
+}
+
+// TestRunFindingTestSuite runs the test suite.
+func TestRunFindingTestSuite(t *testing.T) {
+	suite.Run(t, new(FindingTestSuite))
+}
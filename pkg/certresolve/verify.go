@@ -0,0 +1,184 @@
+package certresolve
+
+import (
+	"context"
+	"crypto/tls"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/certsio/certsio/pkg/grab"
+)
+
+const (
+	defaultVerifyTimeout = 10 * time.Second
+	// maxBodySize bounds the response body read when comparing responses.
+	maxBodySize = 1 << 20
+)
+
+// titleRegex matches the title of an HTML page.
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// VerifyOptions defines the verifier configuration.
+type VerifyOptions struct {
+	// Timeout bounds each handshake and HTTP request.
+	Timeout time.Duration
+	// HTTP also compares the HTTP response of the server with the one of the
+	// resolved addresses, which are the CDN for an origin bypass.
+	HTTP bool
+}
+
+// Evidence is the outcome of verifying a finding against the server the
+// certificate was seen on.
+type Evidence struct {
+	// SNI is the server name sent in the handshake.
+	SNI string `json:"sni"`
+	// Fingerprint is the SHA-256 fingerprint of the certificate presented.
+	Fingerprint string `json:"fingerprint_sha256,omitempty"`
+	// CertificateMatch reports whether the presented certificate is the one of the finding.
+	CertificateMatch bool `json:"certificate_match"`
+	// Error is the error that stopped the handshake, if any.
+	Error string `json:"error,omitempty"`
+	// HTTP compares the HTTP responses, if requested.
+	HTTP *HTTPEvidence `json:"http,omitempty"`
+}
+
+// HTTPEvidence compares the response of the server the certificate was seen
+// on with the response of the resolved addresses for the same Host.
+type HTTPEvidence struct {
+	// Origin is the response of the server the certificate was seen on.
+	Origin *HTTPResponse `json:"origin,omitempty"`
+	// Fronted is the response of the first resolved address.
+	Fronted *HTTPResponse `json:"fronted,omitempty"`
+	// Match reports whether both responses have the same status and title.
+	Match bool `json:"match"`
+}
+
+// HTTPResponse summarizes an HTTP response.
+type HTTPResponse struct {
+	// Address is the host:port the request was sent to.
+	Address string `json:"address"`
+	// Status is the response status code.
+	Status int `json:"status,omitempty"`
+	// Title is the title of an HTML response.
+	Title string `json:"title,omitempty"`
+	// Error is the error that stopped the request, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Verifier confirms findings by connecting to the server the certificate was
+// seen on with the finding host as SNI.
+type Verifier struct {
+	opts VerifyOptions
+}
+
+// NewVerifier creates a new verifier.
+func NewVerifier(opts VerifyOptions) *Verifier {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultVerifyTimeout
+	}
+	return &Verifier{opts: opts}
+}
+
+// Verify handshakes with the server of finding using its host as SNI and
+// checks the certificate presented is the one of the finding. The evidence is
+// attached to finding, and its kind upgraded to confirmed on a match.
+func (v *Verifier) Verify(ctx context.Context, finding *Finding) {
+	evidence := &Evidence{SNI: serverName(finding.Host)}
+	finding.Evidence = evidence
+
+	grabber := grab.New(grab.Config{Timeout: v.opts.Timeout, ServerName: evidence.SNI})
+	certs, err := grabber.Grab(ctx, finding.Server)
+	if err != nil {
+		evidence.Error = err.Error()
+		return
+	}
+	evidence.Fingerprint = certs[0].FingerprintSha256Hash
+	evidence.CertificateMatch = strings.EqualFold(evidence.Fingerprint, finding.Fingerprint)
+	if evidence.CertificateMatch {
+		finding.Kind = finding.Kind.confirmed()
+	}
+
+	if v.opts.HTTP {
+		evidence.HTTP = v.compareHTTP(ctx, finding)
+	}
+}
+
+// serverName returns the SNI to send for host. Wildcards aren't valid server
+// names, so the wildcard label is stripped and the parent name sent instead.
+func serverName(host string) string {
+	return strings.TrimPrefix(host, "*.")
+}
+
+// compareHTTP requests the host from the server and the first resolved address.
+func (v *Verifier) compareHTTP(ctx context.Context, finding *Finding) *HTTPEvidence {
+	_, port, err := net.SplitHostPort(finding.Server)
+	if err != nil {
+		port = "443"
+	}
+
+	host := serverName(finding.Host)
+	evidence := &HTTPEvidence{Origin: v.fetch(ctx, finding.Server, host, port)}
+	if len(finding.ResolvedIPs) == 0 {
+		// nothing fronts an internal host.
+		return evidence
+	}
+	evidence.Fronted = v.fetch(ctx, net.JoinHostPort(finding.ResolvedIPs[0], port), host, port)
+	evidence.Match = evidence.Origin.Error == "" && evidence.Fronted.Error == "" &&
+		evidence.Origin.Status == evidence.Fronted.Status &&
+		evidence.Origin.Title == evidence.Fronted.Title
+
+	return evidence
+}
+
+// fetch requests https://host:port/ from addr, regardless of what host resolves to.
+func (v *Verifier) fetch(ctx context.Context, addr, host, port string) *HTTPResponse {
+	response := &HTTPResponse{Address: addr}
+
+	dialer := &net.Dialer{Timeout: v.opts.Timeout}
+	client := &http.Client{
+		Timeout: v.opts.Timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+			TLSClientConfig: &tls.Config{
+				// the origin is compared, not trusted.
+				InsecureSkipVerify: true, //nolint:gosec
+				ServerName:         host,
+			},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	target := host
+	if port != "443" {
+		target = net.JoinHostPort(host, port)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+target+"/", http.NoBody)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	defer resp.Body.Close()
+
+	response.Status = resp.StatusCode
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if match := titleRegex.FindSubmatch(body); match != nil {
+		response.Title = strings.TrimSpace(html.UnescapeString(string(match[1])))
+	}
+
+	return response
+}
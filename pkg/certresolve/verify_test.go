@@ -0,0 +1,104 @@
+package certresolve
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VerifyTestSuite struct {
+	suite.Suite
+}
+
+// newServer starts a TLS server on addr serving a page titled title.
+func (s *VerifyTestSuite) newServer(addr, title string) *httptest.Server {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.T().Skipf("couldn't listen on %s: %v", addr, err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<html><head><title>%s</title></head></html>", title)
+	}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.StartTLS()
+	s.T().Cleanup(server.Close)
+
+	return server
+}
+
+// TestVerify tests confirming findings against local TLS servers.
+func (s *VerifyTestSuite) TestVerify() {
+	origin := s.newServer("127.0.0.1:0", "App")
+	_, port, _ := net.SplitHostPort(origin.Listener.Addr().String())
+	// the CDN answers on another loopback address, on the same port.
+	s.newServer("127.0.0.2:"+port, "Blocked")
+
+	fingerprint := sha256.Sum256(origin.Certificate().Raw)
+	finding := Finding{
+		Kind:        KindOriginBypass,
+		Host:        "app.example.com",
+		ResolvedIPs: []string{"127.0.0.2"},
+		Server:      origin.Listener.Addr().String(),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}
+
+	confirmed := finding
+	NewVerifier(VerifyOptions{HTTP: true}).Verify(context.Background(), &confirmed)
+	s.Equal(KindConfirmedOriginBypass, confirmed.Kind)
+	s.Require().NotNil(confirmed.Evidence)
+	s.Equal("app.example.com", confirmed.Evidence.SNI)
+	s.True(confirmed.Evidence.CertificateMatch)
+	s.Require().NotNil(confirmed.Evidence.HTTP)
+	s.Equal(&HTTPResponse{Address: finding.Server, Status: http.StatusOK, Title: "App"}, confirmed.Evidence.HTTP.Origin)
+	s.Equal(&HTTPResponse{Address: "127.0.0.2:" + port, Status: http.StatusOK, Title: "Blocked"}, confirmed.Evidence.HTTP.Fronted)
+	s.False(confirmed.Evidence.HTTP.Match)
+	s.Equal([]string{confirmed.Evidence.Fingerprint, "false"}, confirmed.row()[len(findingColumns)-2:])
+
+	// wildcard names are sent without the wildcard label.
+	wildcard := finding
+	wildcard.Host = "*.example.com"
+	NewVerifier(VerifyOptions{}).Verify(context.Background(), &wildcard)
+	s.Equal(KindConfirmedOriginBypass, wildcard.Kind)
+	s.Equal("example.com", wildcard.Evidence.SNI)
+
+	// cancelling the context stops the handshake.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := finding
+	NewVerifier(VerifyOptions{}).Verify(ctx, &cancelled)
+	s.Equal(KindOriginBypass, cancelled.Kind)
+	s.Contains(cancelled.Evidence.Error, "canceled")
+
+	// the server presents another certificate.
+	mismatch := finding
+	mismatch.Fingerprint = "bb"
+	NewVerifier(VerifyOptions{}).Verify(context.Background(), &mismatch)
+	s.Equal(KindOriginBypass, mismatch.Kind)
+	s.False(mismatch.Evidence.CertificateMatch)
+	s.Equal(confirmed.Evidence.Fingerprint, mismatch.Evidence.Fingerprint)
+	s.Nil(mismatch.Evidence.HTTP)
+
+	// the server is gone.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	listener.Close()
+	gone := finding
+	gone.Server = listener.Addr().String()
+	NewVerifier(VerifyOptions{}).Verify(context.Background(), &gone)
+	s.Equal(KindOriginBypass, gone.Kind)
+	s.NotEmpty(gone.Evidence.Error)
+}
+
+// TestRunVerifyTestSuite runs the test suite.
+func TestRunVerifyTestSuite(t *testing.T) {
+	suite.Run(t, new(VerifyTestSuite))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -12,6 +13,17 @@ import (
 type Config struct {
 	BaseURL string `toml:"base_url,omitempty"`
 	APIKey  string `toml:"api_key"`
+	// CacheDir is the directory API responses are cached in (default is the user cache directory).
+	CacheDir string `toml:"cache_dir,omitempty"`
+	// CacheTTL is how long cached API responses are used for, e.g. "24h". Zero disables the cache.
+	CacheTTL time.Duration `toml:"cache_ttl,omitempty"`
+	// Resolvers are the DNS servers used to resolve certificate names, e.g.
+	// "1.1.1.1", "tls://1.1.1.1" or "https://dns.google/dns-query".
+	Resolvers []string `toml:"resolvers,omitempty"`
+	// ResolversFile is a file of DNS servers, one per line, used with Resolvers.
+	ResolversFile string `toml:"resolvers_file,omitempty"`
+	// DNSRetries is the number of attempts for each DNS lookup.
+	DNSRetries int `toml:"dns_retries,omitempty"`
 }
 
 // Get reads the configuration from a TOML file and returns a Config
@@ -0,0 +1,125 @@
+// Package filter selects certificates by their properties.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// Filter reports whether a certificate should be kept.
+type Filter func(cert *certificate.Certificate) bool
+
+// All returns a filter keeping certificates kept by every filter.
+// With no filters every certificate is kept.
+func All(filters ...Filter) Filter {
+	return func(cert *certificate.Certificate) bool {
+		for _, filter := range filters {
+			if !filter(cert) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Not returns a filter keeping the certificates dropped by filter.
+func Not(filter Filter) Filter {
+	return func(cert *certificate.Certificate) bool {
+		return !filter(cert)
+	}
+}
+
+// Expired keeps expired certificates.
+func Expired() Filter {
+	return func(cert *certificate.Certificate) bool {
+		return cert.Expired
+	}
+}
+
+// SelfSigned keeps self-signed certificates.
+func SelfSigned() Filter {
+	return func(cert *certificate.Certificate) bool {
+		return cert.SelfSigned
+	}
+}
+
+// Revoked keeps revoked certificates.
+func Revoked() Filter {
+	return func(cert *certificate.Certificate) bool {
+		return cert.Revoked
+	}
+}
+
+// IssuedAfter keeps certificates valid from after t.
+func IssuedAfter(t time.Time) Filter {
+	return func(cert *certificate.Certificate) bool {
+		return cert.NotBefore.After(t)
+	}
+}
+
+// ExpiresWithin keeps certificates that haven't expired yet but will within d of now.
+func ExpiresWithin(d time.Duration, now time.Time) Filter {
+	return func(cert *certificate.Certificate) bool {
+		return !cert.NotAfter.IsZero() && cert.NotAfter.After(now) && !cert.NotAfter.After(now.Add(d))
+	}
+}
+
+// IssuerOrg keeps certificates with an issuer organization containing org, ignoring case.
+func IssuerOrg(org string) Filter {
+	org = strings.ToLower(org)
+	return func(cert *certificate.Certificate) bool {
+		for _, issuer := range cert.IssuerOrg {
+			if strings.Contains(strings.ToLower(issuer), org) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NameMatches keeps certificates with a name matching re.
+func NameMatches(re *regexp.Regexp) Filter {
+	return func(cert *certificate.Certificate) bool {
+		for _, name := range cert.Names {
+			if re.MatchString(name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ParseDuration parses a duration like time.ParseDuration, also accepting
+// whole days such as "30d".
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("filter: invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("filter: %w", err)
+	}
+	return d, nil
+}
+
+// ParseTime parses a date such as "2024-01-01" or an RFC 3339 time.
+func ParseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("filter: invalid time %q, expected YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, nil
+}
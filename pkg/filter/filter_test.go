@@ -0,0 +1,76 @@
+package filter
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/stretchr/testify/suite"
+)
+
+type FilterTestSuite struct {
+	suite.Suite
+}
+
+// TestFilters tests each filter against a set of certificates.
+func (s *FilterTestSuite) TestFilters() {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	certs := []certificate.Certificate{
+		{Server: "expired", Expired: true, NotBefore: now.AddDate(-2, 0, 0), NotAfter: now.AddDate(-1, 0, 0)},
+		{Server: "soon", NotBefore: now.AddDate(0, -2, 0), NotAfter: now.AddDate(0, 0, 10), IssuerOrg: []string{"Let's Encrypt"}},
+		{Server: "self", SelfSigned: true, NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.AddDate(5, 0, 0), Names: []string{"db.internal"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", All(), []string{"expired", "soon", "self"}},
+		{"exclude expired", Not(Expired()), []string{"soon", "self"}},
+		{"self-signed", SelfSigned(), []string{"self"}},
+		{"issued after", IssuedAfter(now.AddDate(0, -3, 0)), []string{"soon"}},
+		{"expires within", ExpiresWithin(30*24*time.Hour, now), []string{"soon"}},
+		{"issuer org", IssuerOrg("let's encrypt"), []string{"soon"}},
+		{"name regex", NameMatches(regexp.MustCompile(`\.internal$`)), []string{"self"}},
+		{"combined", All(Not(Expired()), Not(SelfSigned())), []string{"soon"}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			var got []string
+			for i := range certs {
+				if tt.filter(&certs[i]) {
+					got = append(got, certs[i].Server)
+				}
+			}
+			s.Equal(tt.want, got)
+		})
+	}
+}
+
+// TestParse tests parsing filter durations and times.
+func (s *FilterTestSuite) TestParse() {
+	d, err := ParseDuration("30d")
+	s.Require().NoError(err)
+	s.Equal(720*time.Hour, d)
+
+	d, err = ParseDuration("12h")
+	s.Require().NoError(err)
+	s.Equal(12*time.Hour, d)
+
+	_, err = ParseDuration("xd")
+	s.Error(err)
+
+	t, err := ParseTime("2024-01-01")
+	s.Require().NoError(err)
+	s.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), t)
+
+	_, err = ParseTime("01/01/2024")
+	s.Error(err)
+}
+
+// TestRunFilterTestSuite runs the test suite.
+func TestRunFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterTestSuite))
+}
@@ -0,0 +1,174 @@
+// Package grab fetches the certificates served by TLS servers.
+package grab
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+const (
+	defaultPort        = "443"
+	defaultTimeout     = 10 * time.Second
+	defaultConcurrency = 10
+)
+
+// Config defines the grabber configuration.
+type Config struct {
+	// Timeout bounds the connection and handshake with each server.
+	Timeout time.Duration
+	// Concurrency is the number of servers grabbed at once by GrabAll.
+	Concurrency int
+	// ServerName overrides the SNI sent, which is the target host unless it is an IP address.
+	ServerName string
+	// NoSNI disables sending SNI.
+	NoSNI bool
+	// Chain includes the intermediate certificates sent by the server after the leaf.
+	Chain bool
+}
+
+// Result is the outcome of grabbing a single target.
+type Result struct {
+	// Target is the grabbed host:port.
+	Target string
+	// Certificates are the certificates served, leaf first.
+	Certificates []certificate.Certificate
+	// Err is the error that stopped the grab, if any.
+	Err error
+}
+
+// Grabber fetches the certificates served by TLS servers.
+type Grabber struct {
+	config Config
+	dialer *net.Dialer
+}
+
+// New creates a new grabber.
+func New(c Config) *Grabber {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.Concurrency < 1 {
+		c.Concurrency = defaultConcurrency
+	}
+	return &Grabber{
+		config: c,
+		dialer: &net.Dialer{Timeout: c.Timeout},
+	}
+}
+
+// Grab does a TLS handshake with target, a host with an optional port, and
+// returns the certificates it served. Server is set to the IP address and
+// port connected to, the way the API records it.
+func (g *Grabber) Grab(ctx context.Context, target string) ([]certificate.Certificate, error) {
+	host, port, err := splitTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.config.Timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		NetDialer: g.dialer,
+		Config: &tls.Config{
+			// the certificates are recorded, not trusted.
+			InsecureSkipVerify: true, //nolint:gosec
+			ServerName:         g.serverName(host),
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("grab %s: %w", target, err)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("grab %s: no certificates served", target)
+	}
+
+	peers := state.PeerCertificates
+	if !g.config.Chain {
+		peers = peers[:1]
+	}
+
+	server := conn.RemoteAddr().String()
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	certs := make([]certificate.Certificate, len(peers))
+	for i, peer := range peers {
+		certs[i] = certificate.FromX509(peer, server)
+		certs[i].Timestamp = timestamp
+	}
+
+	return certs, nil
+}
+
+// GrabAll grabs every target, with up to Concurrency at once, and sends
+// the results to resultChan in no particular order.
+func (g *Grabber) GrabAll(ctx context.Context, targets []string, resultChan chan<- Result) {
+	var (
+		wg    sync.WaitGroup
+		tasks = make(chan string)
+	)
+
+	for i := 0; i < g.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range tasks {
+				certs, err := g.Grab(ctx, target)
+				select {
+				case resultChan <- Result{Target: target, Certificates: certs, Err: err}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	for _, target := range targets {
+		select {
+		case tasks <- target:
+		case <-ctx.Done():
+		}
+	}
+	close(tasks)
+	wg.Wait()
+}
+
+// serverName returns the SNI to send to host.
+func (g *Grabber) serverName(host string) string {
+	switch {
+	case g.config.NoSNI:
+		return ""
+	case g.config.ServerName != "":
+		return g.config.ServerName
+	case net.ParseIP(host) != nil:
+		return ""
+	default:
+		return host
+	}
+}
+
+// splitTarget splits a host:port target, defaulting to port 443.
+func splitTarget(target string) (string, string, error) {
+	host, port, err := net.SplitHostPort(target)
+	if err == nil {
+		return host, port, nil
+	}
+
+	// a bare host or IPv6 address without a port.
+	host = target
+	if len(host) > 1 && host[0] == '[' && host[len(host)-1] == ']' {
+		host = host[1 : len(host)-1]
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("grab: invalid target %q", target)
+	}
+	return host, defaultPort, nil
+}
@@ -0,0 +1,119 @@
+package grab
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GrabTestSuite struct {
+	suite.Suite
+}
+
+// newServer starts a TLS server recording the SNI of each handshake.
+func (s *GrabTestSuite) newServer() (*httptest.Server, func() []string) {
+	var (
+		mu    sync.Mutex
+		names []string
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			names = append(names, hello.ServerName)
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	s.T().Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), names...)
+	}
+}
+
+// TestGrab tests grabbing the certificate of a local server.
+func (s *GrabTestSuite) TestGrab() {
+	server, names := s.newServer()
+	addr := server.Listener.Addr().String()
+	_, port, _ := net.SplitHostPort(addr)
+
+	certs, err := New(Config{}).Grab(context.Background(), "localhost:"+port)
+	s.Require().NoError(err)
+	s.Require().Len(certs, 1)
+	s.Contains(certs[0].Names, "example.com")
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	s.Equal(hex.EncodeToString(fingerprint[:]), certs[0].FingerprintSha256Hash)
+	s.Contains(certs[0].Server, ":"+port)
+
+	_, err = New(Config{ServerName: "override.test"}).Grab(context.Background(), addr)
+	s.Require().NoError(err)
+	_, err = New(Config{}).Grab(context.Background(), addr)
+	s.Require().NoError(err)
+	s.Equal([]string{"localhost", "override.test", ""}, names())
+}
+
+// TestGrabAll tests grabbing several servers, including one that fails.
+func (s *GrabTestSuite) TestGrabAll() {
+	var targets []string
+	for i := 0; i < 3; i++ {
+		server, _ := s.newServer()
+		u, _ := url.Parse(server.URL)
+		targets = append(targets, u.Host)
+	}
+
+	// a listener that never answers the handshake times out.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer listener.Close()
+	targets = append(targets, listener.Addr().String())
+
+	resultChan := make(chan Result)
+	go func() {
+		New(Config{Timeout: 200 * time.Millisecond, Concurrency: 2}).GrabAll(context.Background(), targets, resultChan)
+		close(resultChan)
+	}()
+
+	var ok, failed int
+	for result := range resultChan {
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		ok++
+	}
+	s.Equal(3, ok)
+	s.Equal(1, failed)
+}
+
+// TestSplitTarget tests parsing targets.
+func (s *GrabTestSuite) TestSplitTarget() {
+	for target, want := range map[string][2]string{
+		"example.com":      {"example.com", "443"},
+		"example.com:8443": {"example.com", "8443"},
+		"[::1]:443":        {"::1", "443"},
+		"[::1]":            {"::1", "443"},
+	} {
+		host, port, err := splitTarget(target)
+		s.Require().NoError(err)
+		s.Equal(want, [2]string{host, port})
+	}
+}
+
+// TestRunGrabTestSuite runs the test suite.
+func TestRunGrabTestSuite(t *testing.T) {
+	suite.Run(t, new(GrabTestSuite))
+}
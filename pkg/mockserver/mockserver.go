@@ -0,0 +1,218 @@
+// Package mockserver implements a local stand-in for the certs.io search API.
+package mockserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/search"
+)
+
+const defaultPageSize = 100
+
+// Config defines the mock server behaviour.
+type Config struct {
+	// PageSize is the number of certificates per page.
+	PageSize int
+	// APIKey is the key requests must send in X-RapidAPI-Key. Empty accepts any key.
+	APIKey string
+	// RateLimitRate is the fraction of requests answered with a 429.
+	RateLimitRate float64
+	// UnauthorizedRate is the fraction of requests answered with a 401.
+	UnauthorizedRate float64
+	// Latency is added to every response.
+	Latency time.Duration
+	// Seed seeds the random failure injection.
+	Seed int64
+}
+
+// Server serves the certs.io POST /certificates contract from a fixed set of certificates.
+type Server struct {
+	certs  []certificate.Certificate
+	config Config
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates a mock server for certs.
+func New(certs []certificate.Certificate, c Config) *Server {
+	if c.PageSize <= 0 {
+		c.PageSize = defaultPageSize
+	}
+	return &Server{
+		certs:  certs,
+		config: c,
+		rand:   rand.New(rand.NewSource(c.Seed)),
+	}
+}
+
+// Load reads certificates in certsio JSONL format.
+func Load(r io.Reader) ([]certificate.Certificate, error) {
+	var certs []certificate.Certificate
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var cert certificate.Certificate
+		if err := cert.Unmarshal(scanner.Bytes()); err != nil {
+			return nil, fmt.Errorf("mockserver: line %d: %w", line, err)
+		}
+		certs = append(certs, cert)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("mockserver: %w", err)
+	}
+
+	return certs, nil
+}
+
+// ServeHTTP answers a search request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/certificates" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.config.Latency > 0 {
+		select {
+		case <-time.After(s.config.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if (s.config.APIKey != "" && r.Header.Get("X-RapidAPI-Key") != s.config.APIKey) || s.chance(s.config.UnauthorizedRate) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Invalid API key."})
+		return
+	}
+	if s.chance(s.config.RateLimitRate) {
+		w.Header().Set("Retry-After", "1")
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"message": "Too many requests"})
+		return
+	}
+
+	var query search.Query
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	if _, err := search.ParseField(query.Field.String()); err != nil || query.Value == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid field or term"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.page(query))
+}
+
+// page returns the requested page of certificates matching query. As the
+// client expects, total_pages is the number of the last page.
+func (s *Server) page(query search.Query) search.Response {
+	var matches []certificate.Certificate
+	for _, cert := range s.certs {
+		if Match(cert, query.Field, query.Value) {
+			matches = append(matches, cert)
+		}
+	}
+
+	resp := search.Response{
+		Total:        uint64(len(matches)),
+		CurrentPage:  query.Page,
+		Certificates: []certificate.Certificate{},
+	}
+	if len(matches) > 0 {
+		resp.Pages = uint64((len(matches) - 1) / s.config.PageSize)
+	}
+
+	start := query.Page * uint64(s.config.PageSize)
+	if start < uint64(len(matches)) {
+		end := start + uint64(s.config.PageSize)
+		if end > uint64(len(matches)) {
+			end = uint64(len(matches))
+		}
+		resp.Certificates = matches[start:end]
+	}
+
+	return resp
+}
+
+// chance returns true with probability rate.
+func (s *Server) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < rate
+}
+
+// Match reports whether cert matches a search for value in field.
+func Match(cert certificate.Certificate, field search.Field, value string) bool {
+	value = strings.ToLower(value)
+	switch field {
+	case search.ByDomain:
+		return inDomain(cert.Names, value) || inDomain(cert.ParentDomains, value)
+	case search.ByServer:
+		return strings.ToLower(cert.Server) == value
+	case search.ByFingerprint:
+		return strings.ToLower(cert.FingerprintSha256Hash) == value
+	case search.BySerial:
+		return strings.ToLower(cert.Serial) == value
+	case search.ByEmails:
+		return containsAny(cert.Emails, value)
+	case search.ByOrg:
+		return containsAny(cert.SubjectOrg, value)
+	case search.ByCertNames:
+		for _, name := range cert.Names {
+			if ok, _ := path.Match(value, strings.ToLower(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inDomain reports whether any of names is domain or one of its subdomains.
+func inDomain(names []string, domain string) bool {
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimPrefix(name, "*."))
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether any of values contains the lowercase substr.
+func containsAny(values []string, substr string) bool {
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,67 @@
+package mockserver
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/certsio/certsio/pkg/search"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockServerTestSuite struct {
+	suite.Suite
+}
+
+// TestSearch tests that search.Client can page through the mock server.
+func (s *MockServerTestSuite) TestSearch() {
+	var data strings.Builder
+	for i := 0; i < 25; i++ {
+		fmt.Fprintf(&data, `{"server":"10.0.0.%d:443","ssl_names":["host%d.example.com"],"subject_org":["Example Inc"]}`+"\n", i, i)
+	}
+	data.WriteString(`{"server":"10.0.1.1:443","ssl_names":["db.internal"],"subject_org":["Other"]}` + "\n")
+
+	certs, err := Load(strings.NewReader(data.String()))
+	s.Require().NoError(err)
+	s.Len(certs, 26)
+
+	server := httptest.NewServer(New(certs, Config{PageSize: 10, APIKey: "key"}))
+	defer server.Close()
+
+	client := search.NewClient(config.Config{APIKey: "key", BaseURL: server.URL + "/certificates"})
+
+	s.Run("domain", func() {
+		pager, err := client.Pages(context.Background(), &search.Query{Field: search.ByDomain, Value: "example.com"})
+		s.Require().NoError(err)
+		defer pager.Close()
+		s.Equal(uint64(25), pager.Total())
+		s.Equal(uint64(2), pager.Pages())
+
+		var results []certificate.Certificate
+		for pager.Next() {
+			results = append(results, pager.Page().Certificates...)
+		}
+		s.NoError(pager.Err())
+		s.Len(results, 25)
+	})
+	s.Run("ssl_names wildcard", func() {
+		results, err := client.Search(context.Background(), &search.Query{Field: search.ByCertNames, Value: "*.internal"})
+		s.Require().NoError(err)
+		s.Require().Len(results, 1)
+		s.Equal("10.0.1.1:443", results[0].Server)
+	})
+	s.Run("unauthorized", func() {
+		client := search.NewClient(config.Config{APIKey: "wrong", BaseURL: server.URL + "/certificates"})
+		_, err := client.Search(context.Background(), &search.Query{Field: search.ByOrg, Value: "example"})
+		s.ErrorIs(err, search.ErrUnauthorized)
+	})
+}
+
+// TestRunMockServerTestSuite runs the test suite.
+func TestRunMockServerTestSuite(t *testing.T) {
+	suite.Run(t, new(MockServerTestSuite))
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// DefaultJoiner joins multi-valued fields in flattened output.
+const DefaultJoiner = ";"
+
+// column extracts a certificate field for flattened output.
+type column struct {
+	name string
+	// values returns the field values, a single value unless the field is multi-valued.
+	values func(cert *certificate.Certificate) []string
+}
+
+// value returns the field value, with multiple values joined with joiner.
+func (c column) value(cert *certificate.Certificate, joiner string) string {
+	return strings.Join(c.values(cert), joiner)
+}
+
+// single adapts a single-valued field.
+func single(fn func(cert *certificate.Certificate) string) func(cert *certificate.Certificate) []string {
+	return func(cert *certificate.Certificate) []string {
+		return []string{fn(cert)}
+	}
+}
+
+// columns lists every certificate field by its JSON name, in output order.
+var columns = []column{
+	{"@timestamp", single(func(c *certificate.Certificate) string { return c.Timestamp })},
+	{"server", single(func(c *certificate.Certificate) string { return c.Server })},
+	{"expired", single(func(c *certificate.Certificate) string { return strconv.FormatBool(c.Expired) })},
+	{"self_signed", single(func(c *certificate.Certificate) string { return strconv.FormatBool(c.SelfSigned) })},
+	{"revoked", single(func(c *certificate.Certificate) string { return strconv.FormatBool(c.Revoked) })},
+	{"not_before", single(func(c *certificate.Certificate) string { return formatTime(c.NotBefore) })},
+	{"not_after", single(func(c *certificate.Certificate) string { return formatTime(c.NotAfter) })},
+	{"ssl_names", func(c *certificate.Certificate) []string { return c.Names }},
+	{"subject_org", func(c *certificate.Certificate) []string { return c.SubjectOrg }},
+	{"serial", single(func(c *certificate.Certificate) string { return c.Serial })},
+	{"issuer_names", func(c *certificate.Certificate) []string { return c.IssuerNames }},
+	{"issuer_org", func(c *certificate.Certificate) []string { return c.IssuerOrg }},
+	{"emails", func(c *certificate.Certificate) []string { return c.Emails }},
+	{"fingerprint_sha256", single(func(c *certificate.Certificate) string { return c.FingerprintSha256Hash })},
+	{"parent_domains", func(c *certificate.Certificate) []string { return c.ParentDomains }},
+	{"query_term", single(func(c *certificate.Certificate) string { return c.QueryTerm })},
+}
+
+// tableColumns are the columns shown by the table format.
+var tableColumns = []string{"server", "not_after", "expired", "self_signed", "issuer_org", "ssl_names"}
+
+// FieldNames returns the JSON names of the certificate fields accepted by
+// Options.Fields and Options.Unique.
+func FieldNames() []string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// lookupColumns returns the columns with the given names, or every column if names is empty.
+func lookupColumns(names []string) ([]column, error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+
+	selected := make([]column, 0, len(names))
+	for _, name := range names {
+		col, ok := findColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("output: unknown field %q", name)
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+// findColumn returns the column with the given JSON name.
+func findColumn(name string) (column, bool) {
+	for _, col := range columns {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return column{}, false
+}
+
+// formatTime formats a certificate time, leaving unset times empty.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
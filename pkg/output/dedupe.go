@@ -0,0 +1,51 @@
+package output
+
+import (
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// dedupeWriter drops certificates already written to the next writer.
+type dedupeWriter struct {
+	next       CertificateWriter
+	seen       Set
+	withServer bool
+}
+
+// NewDedupeWriter wraps next, writing each certificate only once. Certificates
+// are identified by their SHA-256 fingerprint, and also by their server if
+// withServer is set, so the same certificate seen on several servers is kept.
+// Certificates without a fingerprint are always written. Flush closes seen.
+func NewDedupeWriter(next CertificateWriter, seen Set, withServer bool) *dedupeWriter {
+	return &dedupeWriter{next: next, seen: seen, withServer: withServer}
+}
+
+// Write writes the certificate if it wasn't written before.
+func (w *dedupeWriter) Write(cert certificate.Certificate) error {
+	if cert.FingerprintSha256Hash == "" {
+		return w.next.Write(cert)
+	}
+
+	key := cert.FingerprintSha256Hash
+	if w.withServer {
+		key += "|" + cert.Server
+	}
+	added, err := w.seen.Add(key)
+	if !added {
+		return err
+	}
+	// the certificate is seen from now on, so it's written even if the set
+	// failed to grow.
+	if writeErr := w.next.Write(cert); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// Flush flushes the next writer and releases the set of certificates seen.
+func (w *dedupeWriter) Flush() error {
+	err := w.next.Flush()
+	if closeErr := w.seen.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// delimitedWriter writes certificates as delimiter-separated values with a header row.
+type delimitedWriter struct {
+	writer        *csv.Writer
+	columns       []column
+	joiner        string
+	headerWritten bool
+}
+
+// NewCSVWriter creates a writer for writing certificates as CSV. Multi-valued
+// fields are joined with joiner.
+func NewCSVWriter(w io.Writer, joiner string) *delimitedWriter {
+	return newDelimitedWriter(w, ',', joiner)
+}
+
+// NewTSVWriter creates a writer for writing certificates as TSV. Multi-valued
+// fields are joined with joiner.
+func NewTSVWriter(w io.Writer, joiner string) *delimitedWriter {
+	return newDelimitedWriter(w, '\t', joiner)
+}
+
+func newDelimitedWriter(w io.Writer, comma rune, joiner string) *delimitedWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	return &delimitedWriter{writer: writer, columns: columns, joiner: joiner}
+}
+
+// Write writes the certificate as a row.
+func (w *delimitedWriter) Write(cert certificate.Certificate) error {
+	if !w.headerWritten {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		row[i] = col.value(&cert, w.joiner)
+	}
+	return w.writer.Write(row)
+}
+
+// Flush writes the buffered rows, and the header if no rows were written.
+func (w *delimitedWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// withColumns limits the output to cols, if set.
+func (w *delimitedWriter) withColumns(cols []column) *delimitedWriter {
+	if len(cols) > 0 {
+		w.columns = cols
+	}
+	return w
+}
+
+func (w *delimitedWriter) writeHeader() error {
+	w.headerWritten = true
+	header := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		header[i] = col.name
+	}
+	return w.writer.Write(header)
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// GroupByParentDomain groups certificates by the registrable domains of their names.
+const GroupByParentDomain = "parent_domain"
+
+// groupWriter counts certificates per value of a field and writes the counts on Flush.
+type groupWriter struct {
+	writer io.Writer
+	name   string
+	values func(cert *certificate.Certificate) []string
+	counts map[string]int
+}
+
+// NewGroupWriter creates a writer for counting certificates per value of
+// field, or per registrable domain for GroupByParentDomain. A certificate
+// counts once towards each of its values. The counts are written as a table,
+// largest first, on Flush.
+func NewGroupWriter(w io.Writer, field string, opts certificate.DomainOptions) (*groupWriter, error) {
+	g := &groupWriter{writer: w, name: field, counts: make(map[string]int)}
+
+	if field == GroupByParentDomain {
+		g.values = func(cert *certificate.Certificate) []string {
+			if domains := cert.RegistrableDomains(opts); len(domains) > 0 {
+				return domains
+			}
+			return cert.ParentDomains
+		}
+		return g, nil
+	}
+
+	col, ok := findColumn(field)
+	if !ok {
+		return nil, fmt.Errorf("output: unknown field %q", field)
+	}
+	g.values = col.values
+	return g, nil
+}
+
+// Write counts the certificate.
+func (w *groupWriter) Write(cert certificate.Certificate) error {
+	seen := make(map[string]bool)
+	for _, value := range w.values(&cert) {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		w.counts[value]++
+	}
+	return nil
+}
+
+// Flush writes the counts.
+func (w *groupWriter) Flush() error {
+	values := make([]string, 0, len(w.counts))
+	for value := range w.counts {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if w.counts[values[i]] != w.counts[values[j]] {
+			return w.counts[values[i]] > w.counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+
+	tw := tabwriter.NewWriter(w.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "COUNT\t%s\n", strings.ToUpper(w.name))
+	for _, value := range values {
+		fmt.Fprintf(tw, "%d\t%s\n", w.counts[value], value)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"io"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// jsonWriter writes certificates as a single JSON array.
+type jsonWriter struct {
+	writer  io.Writer
+	fields  []column
+	written bool
+}
+
+// NewJSONWriter creates a writer for writing certificates as a JSON array.
+func NewJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{writer: w}
+}
+
+// Write writes the certificate as the next array element.
+func (w *jsonWriter) Write(cert certificate.Certificate) error {
+	sep := ",\n"
+	if !w.written {
+		sep = "[\n"
+	}
+	data, err := marshal(&cert, w.fields)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w.writer, sep); err != nil {
+		return err
+	}
+	w.written = true
+	_, err = w.writer.Write(data)
+	return err
+}
+
+// Flush closes the array.
+func (w *jsonWriter) Flush() error {
+	end := "\n]\n"
+	if !w.written {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w.writer, end)
+	return err
+}
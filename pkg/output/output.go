@@ -1,30 +1,145 @@
 package output
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 
 	"github.com/certsio/certsio/pkg/certificate"
 	jsoniter "github.com/json-iterator/go"
 )
 
+// Format is an output format.
+type Format string
+
+const (
+	// FormatJSONL writes one JSON object per line.
+	FormatJSONL Format = "jsonl"
+	// FormatJSON writes a single JSON array.
+	FormatJSON Format = "json"
+	// FormatCSV writes comma-separated values with a header row.
+	FormatCSV Format = "csv"
+	// FormatTSV writes tab-separated values with a header row.
+	FormatTSV Format = "tsv"
+	// FormatTable writes an aligned table for terminals.
+	FormatTable Format = "table"
+	// FormatTemplate writes each certificate with a Go template.
+	FormatTemplate Format = "template"
+)
+
+// Formats lists every output format.
+var Formats = []Format{FormatJSONL, FormatJSON, FormatCSV, FormatTSV, FormatTable, FormatTemplate}
+
 // CertificateWriter writes certificates to a destination.
 type CertificateWriter interface {
 	Write(cert certificate.Certificate) error
+	// Flush writes any buffered output. It must be called once all certificates were written.
+	Flush() error
+}
+
+// Options configures the writer returned by New.
+type Options struct {
+	// Format is the output format, FormatJSONL if empty.
+	Format Format
+	// Joiner joins multi-valued fields in flattened formats, DefaultJoiner if empty.
+	Joiner string
+	// Template is the Go template used by FormatTemplate.
+	Template string
+	// Fields limits the output to these fields, named as in the JSON output.
+	// The table format shows a default set of fields if empty, the others every field.
+	Fields []string
+	// Unique writes each distinct value of this field once, one per line,
+	// instead of certificates in Format.
+	Unique string
+	// Seen tracks the values written with Unique, a memory set if nil.
+	Seen Set
+	// GroupBy writes the number of certificates per value of this field, or
+	// per registrable domain for GroupByParentDomain, instead of certificates.
+	GroupBy string
+	// Domains controls how registrable domains are derived for GroupByParentDomain.
+	Domains certificate.DomainOptions
+}
+
+// New creates a writer for the configured format.
+func New(w io.Writer, opts Options) (CertificateWriter, error) {
+	if opts.Joiner == "" {
+		opts.Joiner = DefaultJoiner
+	}
+
+	if opts.GroupBy != "" {
+		if opts.Unique != "" || len(opts.Fields) > 0 {
+			return nil, fmt.Errorf("output: fields can't be selected when grouping")
+		}
+		return NewGroupWriter(w, opts.GroupBy, opts.Domains)
+	}
+
+	if opts.Unique != "" {
+		if len(opts.Fields) > 0 {
+			return nil, fmt.Errorf("output: fields can't be selected for unique values")
+		}
+		if opts.Seen == nil {
+			opts.Seen = NewMemorySet()
+		}
+		return NewUniqueWriter(w, opts.Unique, opts.Seen)
+	}
+
+	var fields []column
+	if len(opts.Fields) > 0 {
+		var err error
+		if fields, err = lookupColumns(opts.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	switch opts.Format {
+	case "", FormatJSONL:
+		return &writer{writer: w, fields: fields}, nil
+	case FormatJSON:
+		return &jsonWriter{writer: w, fields: fields}, nil
+	case FormatCSV:
+		return NewCSVWriter(w, opts.Joiner).withColumns(fields), nil
+	case FormatTSV:
+		return NewTSVWriter(w, opts.Joiner).withColumns(fields), nil
+	case FormatTable:
+		return NewTableWriter(w, opts.Joiner).withColumns(fields), nil
+	case FormatTemplate:
+		if len(fields) > 0 {
+			return nil, fmt.Errorf("output: fields can't be selected for the template format")
+		}
+		return NewTemplateWriter(w, opts.Template)
+	default:
+		return nil, fmt.Errorf("output: unknown format %q", opts.Format)
+	}
 }
 
 // writer is a writer for writing certificates.
 type writer struct {
 	writer io.Writer
+	fields []column
 }
 
-// NewWriter creates a new writer for writing certificates.
+// NewWriter creates a new writer for writing certificates as JSON lines.
 func NewWriter(w io.Writer) *writer {
 	return &writer{writer: w}
 }
 
 // Write writes the event to file and/or screen.
 func (w *writer) Write(cert certificate.Certificate) error {
-	return writeCertificate(w.writer, cert)
+	if w.fields == nil {
+		return writeCertificate(w.writer, cert)
+	}
+
+	data, err := marshal(&cert, w.fields)
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(append(data, '\n'))
+	return err
+}
+
+// Flush is a no-op, every certificate is written immediately.
+func (w *writer) Flush() error {
+	return nil
 }
 
 // Write writes the event to file and/or screen.
@@ -37,3 +152,36 @@ func writeCertificate(w io.Writer, cert certificate.Certificate) error {
 
 	return nil
 }
+
+// marshal encodes the certificate as JSON, keeping only fields if set.
+func marshal(cert *certificate.Certificate, fields []column) ([]byte, error) {
+	data, err := jsoniter.Marshal(cert)
+	if err != nil || fields == nil {
+		return data, err
+	}
+
+	var object map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	// build the object by hand to keep the requested field order.
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range fields {
+		value, ok := object[field.name]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := jsoniter.Marshal(field.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,182 @@
+package output
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputTestSuite struct {
+	suite.Suite
+}
+
+var testCerts = []certificate.Certificate{
+	{Server: "10.0.0.1:443", Names: []string{"a.example.com", "b.example.com"}, IssuerOrg: []string{"Example CA"}},
+	{Server: "10.0.0.2:443", Names: []string{"c.example.com"}, SelfSigned: true},
+}
+
+// write writes testCerts with the given options.
+func (s *OutputTestSuite) write(opts Options, certs []certificate.Certificate) string {
+	var buf bytes.Buffer
+	w, err := New(&buf, opts)
+	s.Require().NoError(err)
+	for _, cert := range certs {
+		s.Require().NoError(w.Write(cert))
+	}
+	s.Require().NoError(w.Flush())
+	return buf.String()
+}
+
+// TestFormats tests each output format.
+func (s *OutputTestSuite) TestFormats() {
+	s.Run("json", func() {
+		out := s.write(Options{Format: FormatJSON}, testCerts)
+		var decoded []certificate.Certificate
+		s.Require().NoError(jsoniter.Unmarshal([]byte(out), &decoded))
+		s.Equal(testCerts[1].Server, decoded[1].Server)
+		s.Equal("[]\n", s.write(Options{Format: FormatJSON}, nil))
+	})
+	s.Run("csv", func() {
+		out := s.write(Options{Format: FormatCSV, Joiner: "|"}, testCerts)
+		s.Contains(out, "@timestamp,server,expired,")
+		s.Contains(out, "a.example.com|b.example.com")
+	})
+	s.Run("tsv", func() {
+		out := s.write(Options{Format: FormatTSV}, testCerts)
+		s.Contains(out, "\t10.0.0.2:443\tfalse\ttrue\t")
+		s.Contains(out, "a.example.com;b.example.com")
+	})
+	s.Run("table", func() {
+		out := s.write(Options{Format: FormatTable}, testCerts)
+		s.Contains(out, "SERVER        NOT_AFTER")
+		s.Contains(out, "10.0.0.2:443  -")
+	})
+	s.Run("template", func() {
+		out := s.write(Options{Format: FormatTemplate, Template: `{{.Server}} {{join .Names ","}}`}, testCerts)
+		s.Equal("10.0.0.1:443 a.example.com,b.example.com\n10.0.0.2:443 c.example.com\n", out)
+
+		_, err := New(&bytes.Buffer{}, Options{Format: FormatTemplate, Template: "{{.Server"})
+		s.Error(err)
+	})
+	s.Run("unknown", func() {
+		_, err := New(&bytes.Buffer{}, Options{Format: "xml"})
+		s.Error(err)
+	})
+}
+
+// TestFields tests limiting the output to some fields.
+func (s *OutputTestSuite) TestFields() {
+	fields := []string{"server", "ssl_names"}
+	s.Equal(`{"server":"10.0.0.2:443","ssl_names":["c.example.com"]}`+"\n", s.write(Options{Fields: fields}, testCerts[1:]))
+	s.Equal("server,ssl_names\n10.0.0.2:443,c.example.com\n", s.write(Options{Format: FormatCSV, Fields: fields}, testCerts[1:]))
+
+	_, err := New(&bytes.Buffer{}, Options{Fields: []string{"nope"}})
+	s.Error(err)
+}
+
+// TestUnique tests writing the distinct values of a field.
+func (s *OutputTestSuite) TestUnique() {
+	certs := append(testCerts, certificate.Certificate{Server: "10.0.0.3:443", Names: []string{"b.example.com", "d.example.com"}})
+	want := "a.example.com\nb.example.com\nc.example.com\nd.example.com\n"
+
+	s.Equal(want, s.write(Options{Unique: "ssl_names"}, certs))
+
+	seen, err := NewDiskSet(s.T().TempDir())
+	s.Require().NoError(err)
+	s.Equal(want, s.write(Options{Unique: "ssl_names", Seen: seen}, certs))
+}
+
+// TestDiskSet tests that the disk set keeps its keys when it grows.
+func (s *OutputTestSuite) TestDiskSet() {
+	set, err := NewDiskSet(s.T().TempDir())
+	s.Require().NoError(err)
+	defer set.Close()
+
+	n := diskInitialSlots
+	for i := 0; i < n; i++ {
+		added, err := set.Add(strconv.Itoa(i))
+		s.Require().NoError(err)
+		s.Require().True(added)
+	}
+	for i := 0; i < n; i += 997 {
+		added, err := set.Add(strconv.Itoa(i))
+		s.Require().NoError(err)
+		s.False(added)
+	}
+}
+
+// TestDedupe tests dropping repeated certificates by fingerprint.
+func (s *OutputTestSuite) TestDedupe() {
+	certs := []certificate.Certificate{
+		{Server: "10.0.0.1:443", FingerprintSha256Hash: "aa"},
+		{Server: "10.0.0.2:443", FingerprintSha256Hash: "aa"},
+		{Server: "10.0.0.1:443", FingerprintSha256Hash: "aa"},
+		{Server: "10.0.0.3:443", FingerprintSha256Hash: "bb"},
+		{Server: "10.0.0.4:443"},
+		{Server: "10.0.0.4:443"},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		withServer bool
+		want       string
+	}{
+		{"fingerprint", false, "10.0.0.1:443\n10.0.0.3:443\n10.0.0.4:443\n10.0.0.4:443\n"},
+		{"fingerprint and server", true, "10.0.0.1:443\n10.0.0.2:443\n10.0.0.3:443\n10.0.0.4:443\n10.0.0.4:443\n"},
+	} {
+		s.Run(tt.name, func() {
+			var buf bytes.Buffer
+			next, err := NewTemplateWriter(&buf, "{{.Server}}")
+			s.Require().NoError(err)
+
+			// a threshold of one spills to disk on the second certificate.
+			w := NewDedupeWriter(next, NewSpillSet(1, s.T().TempDir()), tt.withServer)
+			for _, cert := range certs {
+				s.Require().NoError(w.Write(cert))
+			}
+			s.Require().NoError(w.Flush())
+			s.Equal(tt.want, buf.String())
+		})
+	}
+}
+
+// TestSpillFailure tests that records whose key was added are written when the set fails to spill.
+func (s *OutputTestSuite) TestSpillFailure() {
+	// the spill directory doesn't exist, so spilling fails on the second key.
+	missing := filepath.Join(s.T().TempDir(), "missing")
+
+	var buf bytes.Buffer
+	next, err := NewTemplateWriter(&buf, "{{.Server}}")
+	s.Require().NoError(err)
+	w := NewDedupeWriter(next, NewSpillSet(1, missing), false)
+	s.Require().NoError(w.Write(certificate.Certificate{Server: "10.0.0.1:443", FingerprintSha256Hash: "aa"}))
+	s.Error(w.Write(certificate.Certificate{Server: "10.0.0.2:443", FingerprintSha256Hash: "bb"}))
+	s.Equal("10.0.0.1:443\n10.0.0.2:443\n", buf.String())
+
+	buf.Reset()
+	unique, err := New(&buf, Options{Unique: "server", Seen: NewSpillSet(1, missing)})
+	s.Require().NoError(err)
+	s.Require().NoError(unique.Write(testCerts[0]))
+	s.Error(unique.Write(testCerts[1]))
+	s.Equal("10.0.0.1:443\n10.0.0.2:443\n", buf.String())
+}
+
+// TestGroupBy tests counting certificates per registrable domain.
+func (s *OutputTestSuite) TestGroupBy() {
+	certs := append(testCerts,
+		certificate.Certificate{Names: []string{"*.example.com", "www.example.org"}},
+		certificate.Certificate{ParentDomains: []string{"example.net"}},
+	)
+	out := s.write(Options{GroupBy: GroupByParentDomain}, certs)
+	s.Equal("COUNT  PARENT_DOMAIN\n3      example.com\n1      example.net\n1      example.org\n", out)
+}
+
+// TestRunOutputTestSuite runs the test suite.
+func TestRunOutputTestSuite(t *testing.T) {
+	suite.Run(t, new(OutputTestSuite))
+}
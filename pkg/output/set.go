@@ -0,0 +1,240 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Set tracks the keys seen by deduplicating writers.
+type Set interface {
+	// Add adds key to the set and reports whether it wasn't already present.
+	// A key can be added even when an error is returned, if the set failed
+	// to grow afterwards.
+	Add(key string) (bool, error)
+	// Close releases the set.
+	Close() error
+}
+
+// memorySet is a Set held in memory.
+type memorySet map[string]struct{}
+
+// NewMemorySet creates a set held in memory.
+func NewMemorySet() Set {
+	return memorySet{}
+}
+
+// Add adds key to the set.
+func (s memorySet) Add(key string) (bool, error) {
+	if _, ok := s[key]; ok {
+		return false, nil
+	}
+	s[key] = struct{}{}
+	return true, nil
+}
+
+// Close is a no-op.
+func (s memorySet) Close() error {
+	return nil
+}
+
+const (
+	// diskSlotSize is the size of a disk set slot, a truncated SHA-256 of the key.
+	diskSlotSize = 16
+	// diskInitialSlots is the initial number of slots in a disk set.
+	diskInitialSlots = 1 << 16
+)
+
+// diskSet is a Set stored in a temporary file as an open-addressing hash
+// table of key hashes, so memory use doesn't grow with the number of keys.
+type diskSet struct {
+	dir   string
+	file  *os.File
+	slots uint64
+	count uint64
+}
+
+// NewDiskSet creates a set stored in a temporary file in dir, or in the
+// default temporary directory if dir is empty. The file is removed on Close.
+func NewDiskSet(dir string) (Set, error) {
+	s := &diskSet{dir: dir}
+	file, err := s.create(diskInitialSlots)
+	if err != nil {
+		return nil, err
+	}
+	s.file, s.slots = file, diskInitialSlots
+	return s, nil
+}
+
+// Add adds key to the set.
+func (s *diskSet) Add(key string) (bool, error) {
+	sum := sha256.Sum256([]byte(key))
+	slot := sum[:diskSlotSize]
+	// an all-zero slot marks an empty slot.
+	slot[diskSlotSize-1] |= 1
+
+	added, err := s.insert(s.file, s.slots, slot)
+	if err != nil || !added {
+		return false, err
+	}
+
+	// keep the table at most half full so probe sequences stay short.
+	s.count++
+	if s.count*2 > s.slots {
+		if err := s.grow(); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Close removes the set file.
+func (s *diskSet) Close() error {
+	err := s.file.Close()
+	if rmErr := os.Remove(s.file.Name()); err == nil {
+		err = rmErr
+	}
+	if err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	return nil
+}
+
+// insert adds slot to the table in file with the given number of slots.
+func (s *diskSet) insert(file *os.File, slots uint64, slot []byte) (bool, error) {
+	buf := make([]byte, diskSlotSize)
+	empty := make([]byte, diskSlotSize)
+
+	i := binary.LittleEndian.Uint64(slot) % slots
+	for {
+		if _, err := file.ReadAt(buf, int64(i*diskSlotSize)); err != nil {
+			return false, fmt.Errorf("output: %w", err)
+		}
+		switch {
+		case bytes.Equal(buf, slot):
+			return false, nil
+		case bytes.Equal(buf, empty):
+			if _, err := file.WriteAt(slot, int64(i*diskSlotSize)); err != nil {
+				return false, fmt.Errorf("output: %w", err)
+			}
+			return true, nil
+		}
+		i = (i + 1) % slots
+	}
+}
+
+// grow doubles the table, rehashing every slot into a new file.
+func (s *diskSet) grow() error {
+	slots := s.slots * 2
+	file, err := s.create(slots)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return fmt.Errorf("output: %w", err)
+	}
+	reader := bufio.NewReader(s.file)
+	slot := make([]byte, diskSlotSize)
+	empty := make([]byte, diskSlotSize)
+	for {
+		if _, err := io.ReadFull(reader, slot); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			file.Close()
+			os.Remove(file.Name())
+			return fmt.Errorf("output: %w", err)
+		}
+		if bytes.Equal(slot, empty) {
+			continue
+		}
+		if _, err := s.insert(file, slots, slot); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return err
+		}
+	}
+
+	old := s.file
+	s.file, s.slots = file, slots
+	old.Close()
+	os.Remove(old.Name())
+	return nil
+}
+
+// create creates an empty table file with the given number of slots.
+func (s *diskSet) create(slots uint64) (*os.File, error) {
+	file, err := os.CreateTemp(s.dir, "certsio-set-*")
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	if err := file.Truncate(int64(slots * diskSlotSize)); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	return file, nil
+}
+
+// spillSet is a Set held in memory until it grows past a threshold, after
+// which it moves to disk.
+type spillSet struct {
+	dir       string
+	threshold int
+	memory    memorySet
+	disk      Set
+}
+
+// NewSpillSet creates a set held in memory until it holds more than
+// threshold keys, when it moves to a disk set in dir.
+func NewSpillSet(threshold int, dir string) Set {
+	return &spillSet{dir: dir, threshold: threshold, memory: memorySet{}}
+}
+
+// Add adds key to the set.
+func (s *spillSet) Add(key string) (bool, error) {
+	if s.disk != nil {
+		return s.disk.Add(key)
+	}
+
+	added, _ := s.memory.Add(key)
+	if added && len(s.memory) > s.threshold {
+		if err := s.spill(); err != nil {
+			return true, err
+		}
+	}
+	return added, nil
+}
+
+// Close releases the disk set, if any.
+func (s *spillSet) Close() error {
+	s.memory = nil
+	if s.disk == nil {
+		return nil
+	}
+	return s.disk.Close()
+}
+
+// spill moves the keys held in memory to a disk set.
+func (s *spillSet) spill() error {
+	disk, err := NewDiskSet(s.dir)
+	if err != nil {
+		return err
+	}
+	for key := range s.memory {
+		if _, err := disk.Add(key); err != nil {
+			disk.Close()
+			return err
+		}
+	}
+	s.disk, s.memory = disk, nil
+	return nil
+}
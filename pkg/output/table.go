@@ -0,0 +1,81 @@
+package output
+
+import (
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// tableWriter writes certificates as an aligned table. Columns are aligned
+// across every row, so nothing is written until Flush.
+type tableWriter struct {
+	writer        *tabwriter.Writer
+	columns       []column
+	joiner        string
+	headerWritten bool
+}
+
+// NewTableWriter creates a writer for writing certificates as a table for
+// terminals. Multi-valued fields are joined with joiner.
+func NewTableWriter(w io.Writer, joiner string) *tableWriter {
+	cols, _ := lookupColumns(tableColumns)
+	return &tableWriter{
+		writer:  tabwriter.NewWriter(w, 0, 0, 2, ' ', 0),
+		columns: cols,
+		joiner:  joiner,
+	}
+}
+
+// Write adds the certificate as a row.
+func (w *tableWriter) Write(cert certificate.Certificate) error {
+	if !w.headerWritten {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		row[i] = cell(col.value(&cert, w.joiner))
+	}
+	_, err := io.WriteString(w.writer, strings.Join(row, "\t")+"\n")
+	return err
+}
+
+// Flush writes the aligned table.
+func (w *tableWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	return w.writer.Flush()
+}
+
+// withColumns limits the output to cols, if set.
+func (w *tableWriter) withColumns(cols []column) *tableWriter {
+	if len(cols) > 0 {
+		w.columns = cols
+	}
+	return w
+}
+
+func (w *tableWriter) writeHeader() error {
+	w.headerWritten = true
+	header := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		header[i] = strings.ToUpper(col.name)
+	}
+	_, err := io.WriteString(w.writer, strings.Join(header, "\t")+"\n")
+	return err
+}
+
+// cell makes a value safe for a table cell.
+func cell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// templateFuncs are the functions available to output templates.
+var templateFuncs = template.FuncMap{
+	"join":  func(values []string, sep string) string { return strings.Join(values, sep) },
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// templateWriter writes each certificate with a Go template.
+type templateWriter struct {
+	writer   io.Writer
+	template *template.Template
+}
+
+// NewTemplateWriter creates a writer executing text, a Go template, for each
+// certificate, e.g. `{{.Server}} {{join .Names ","}}`. A newline is added
+// after each certificate unless the template ends with one.
+func NewTemplateWriter(w io.Writer, text string) (*templateWriter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("output: empty template")
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	return &templateWriter{writer: w, template: tmpl}, nil
+}
+
+// Write executes the template for the certificate.
+func (w *templateWriter) Write(cert certificate.Certificate) error {
+	return w.template.Execute(w.writer, &cert)
+}
+
+// Flush is a no-op, every certificate is written immediately.
+func (w *templateWriter) Flush() error {
+	return nil
+}
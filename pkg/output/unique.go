@@ -0,0 +1,56 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// uniqueWriter writes the distinct values of a single field, one per line.
+type uniqueWriter struct {
+	writer io.Writer
+	column column
+	seen   Set
+}
+
+// NewUniqueWriter creates a writer for writing each distinct value of field
+// once, tracking the values already written in seen. Multi-valued fields are
+// flattened and empty values are skipped. Flush closes seen.
+func NewUniqueWriter(w io.Writer, field string, seen Set) (*uniqueWriter, error) {
+	col, ok := findColumn(field)
+	if !ok {
+		return nil, fmt.Errorf("output: unknown field %q", field)
+	}
+	return &uniqueWriter{writer: w, column: col, seen: seen}, nil
+}
+
+// Write writes the values of the field not written before.
+func (w *uniqueWriter) Write(cert certificate.Certificate) error {
+	for _, value := range w.column.values(&cert) {
+		if value == "" {
+			continue
+		}
+		added, err := w.seen.Add(value)
+		if !added {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		// the value is seen from now on, so it's written even if the set
+		// failed to grow.
+		if _, writeErr := io.WriteString(w.writer, value+"\n"); writeErr != nil {
+			return writeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush releases the set of values seen.
+func (w *uniqueWriter) Flush() error {
+	return w.seen.Close()
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"context"
+	"sync"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// TermResult summarizes the search for one term of a batch.
+type TermResult struct {
+	// Term is the searched value.
+	Term string
+	// Total is the total number of certificates reported by the API.
+	Total uint64
+	// Received is the number of certificates sent to the result channel.
+	Received int
+	// Err is the error that stopped the search, if any.
+	Err error
+}
+
+// StreamBatch searches field for every term, with up to workers terms in
+// flight at once, and streams the certificates to resultChan. Each certificate
+// is tagged with the term that produced it. A failed term doesn't stop the
+// others, the returned results report the outcome of each term in order.
+func (c *Client) StreamBatch(ctx context.Context, field Field, terms []string, workers int, resultChan chan<- []certificate.Certificate) []TermResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]TermResult, len(terms))
+		indexes = make(chan int)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = c.streamTerm(ctx, &Query{Field: field, Value: terms[i]}, resultChan)
+			}
+		}()
+	}
+
+	for i := range terms {
+		if ctx.Err() != nil {
+			results[i] = TermResult{Term: terms[i], Err: ctx.Err()}
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// streamTerm streams every page for a single term of a batch.
+func (c *Client) streamTerm(ctx context.Context, query *Query, resultChan chan<- []certificate.Certificate) TermResult {
+	result := TermResult{Term: query.Value}
+
+	pager, err := c.Pages(ctx, query)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer pager.Close()
+	result.Total = pager.Total()
+
+	for pager.Next() {
+		certs := pager.Page().Certificates
+		for i := range certs {
+			certs[i].QueryTerm = query.Value
+		}
+
+		select {
+		case resultChan <- certs:
+			result.Received += len(certs)
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			return result
+		}
+	}
+	result.Err = pager.Err()
+
+	return result
+}
@@ -0,0 +1,217 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/certsio/certsio/pkg/config"
+)
+
+// Cache stores successful API responses on disk, keyed by API base URL,
+// field, term and page.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// CacheEntry describes a cached response.
+type CacheEntry struct {
+	// BaseURL is the API the response came from.
+	BaseURL string
+	// Query is the query the response answers.
+	Query Query
+	// CreatedAt is when the response was cached.
+	CreatedAt time.Time
+	// Size is the size of the entry on disk in bytes.
+	Size int64
+	// Expired reports whether the entry is older than the cache TTL.
+	Expired bool
+}
+
+// cacheFile is the on-disk format of a cache entry.
+type cacheFile struct {
+	BaseURL   string          `json:"base_url"`
+	Query     Query           `json:"query"`
+	CreatedAt time.Time       `json:"created_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// NewCache returns a cache in dir whose entries expire after ttl.
+func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	return &Cache{dir: dir, ttl: ttl}, nil
+}
+
+// DefaultCacheDir returns the default cache directory in the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cache: %w", err)
+	}
+	return filepath.Join(dir, "certsio"), nil
+}
+
+// NewCacheFromConfig returns the cache configured in cfg, which lives in
+// DefaultCacheDir unless cfg.CacheDir is set.
+func NewCacheFromConfig(cfg config.Config) (*Cache, error) {
+	dir := cfg.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+	return NewCache(dir, cfg.CacheTTL)
+}
+
+// Dir returns the cache directory.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns the cached response body of the API at baseURL for query if
+// it hasn't expired.
+func (c *Cache) Get(baseURL string, query Query) ([]byte, bool) {
+	entry, err := c.read(c.path(baseURL, query))
+	if err != nil || entry.BaseURL != baseURL || entry.Query != query || c.expired(entry.CreatedAt) {
+		return nil, false
+	}
+	return entry.Body, true
+}
+
+// Put caches the response body of the API at baseURL for query.
+func (c *Cache) Put(baseURL string, query Query, body []byte) error {
+	data, err := json.Marshal(cacheFile{BaseURL: baseURL, Query: query, CreatedAt: time.Now().UTC(), Body: body})
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	// write to a temporary file first so concurrent readers never see a partial entry.
+	path := c.path(baseURL, query)
+	tmp, err := os.CreateTemp(c.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	return nil
+}
+
+// Entries lists the cached responses, oldest first.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CacheEntry, 0, len(files))
+	for _, path := range files {
+		entry, err := c.read(path)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, CacheEntry{
+			BaseURL:   entry.BaseURL,
+			Query:     entry.Query,
+			CreatedAt: entry.CreatedAt,
+			Size:      info.Size(),
+			Expired:   c.expired(entry.CreatedAt),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+
+	return entries, nil
+}
+
+// Prune removes expired and unreadable entries and returns how many were removed.
+func (c *Cache) Prune() (int, error) {
+	return c.remove(func(path string) bool {
+		entry, err := c.read(path)
+		return err != nil || c.expired(entry.CreatedAt)
+	})
+}
+
+// Clear removes every entry and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	return c.remove(func(string) bool { return true })
+}
+
+// remove deletes the entries matching fn.
+func (c *Cache) remove(fn func(path string) bool) (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+	for _, path := range files {
+		if !fn(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("cache: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// files returns the paths of the cache entries.
+func (c *Cache) files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	return files, nil
+}
+
+// read decodes the cache entry at path.
+func (c *Cache) read(path string) (*cacheFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheFile
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// expired reports whether an entry created at t is older than the TTL.
+func (c *Cache) expired(t time.Time) bool {
+	return c.ttl > 0 && time.Since(t) > c.ttl
+}
+
+// path returns the entry path for query to the API at baseURL.
+func (c *Cache) path(baseURL string, query Query) string {
+	key := strings.Join([]string{baseURL, query.Field.String(), query.Value, strconv.FormatUint(query.Page, 10)}, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
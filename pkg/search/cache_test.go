@@ -0,0 +1,90 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchCacheTestSuite struct {
+	suite.Suite
+}
+
+// TestCachedSearch tests that cache hits skip the API.
+func (s *SearchCacheTestSuite) TestCachedSearch() {
+	var requests int
+	client := NewClient(config.Config{})
+	client.transport.httpClient.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+			body, _ := json.Marshal(Response{Certificates: []certificate.Certificate{{Server: "127.0.0.1:443"}}})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	cache, err := NewCache(s.T().TempDir(), time.Hour)
+	s.Require().NoError(err)
+	client.WithCache(cache)
+
+	query := Query{Field: ByDomain, Value: "example.com"}
+	for i := 0; i < 2; i++ {
+		results, err := client.Search(context.Background(), &query)
+		s.Require().NoError(err)
+		s.Len(results, 1)
+	}
+	s.Equal(1, requests)
+
+	entries, err := cache.Entries()
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Equal(query, entries[0].Query)
+	s.False(entries[0].Expired)
+
+	// entries past the TTL are ignored and pruned.
+	cache.ttl = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, ok := cache.Get(_baseURL, query)
+	s.False(ok)
+
+	removed, err := cache.Prune()
+	s.Require().NoError(err)
+	s.Equal(1, removed)
+
+	_, err = client.Search(context.Background(), &query)
+	s.Require().NoError(err)
+	s.Equal(2, requests)
+
+	// responses of another API aren't shared.
+	cache.ttl = time.Hour
+	client.WithBaseURL("http://127.0.0.1:8080/certificates")
+	_, err = client.Search(context.Background(), &query)
+	s.Require().NoError(err)
+	s.Equal(3, requests)
+	_, err = client.Search(context.Background(), &query)
+	s.Require().NoError(err)
+	s.Equal(3, requests)
+
+	removed, err = cache.Clear()
+	s.Require().NoError(err)
+	s.Equal(2, removed)
+
+	// a failure to cache doesn't fail a successful search.
+	s.Require().NoError(os.RemoveAll(cache.Dir()))
+	results, err := client.Search(context.Background(), &query)
+	s.Require().NoError(err)
+	s.Len(results, 1)
+}
+
+// TestRunCacheTestSuite runs the test suite.
+func TestRunCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchCacheTestSuite))
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CassetteFile is the name of the cassette file within a cassette directory.
+const CassetteFile = "cassette.jsonl"
+
+// interaction is a recorded request and response pair.
+type interaction struct {
+	Request  Query            `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+// recordedResponse is the part of an HTTP response kept in a cassette.
+type recordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records every API request and
+// response to a cassette file, for use with TransportConfig.HTTPTransport.
+// Request headers, including the API key, are not recorded.
+type Recorder struct {
+	next http.RoundTripper
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder returns a Recorder appending to the cassette in dir. Requests are
+// sent with next, or http.DefaultTransport if next is nil.
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, CassetteFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+
+	return &Recorder{next: next, file: file}, nil
+}
+
+// RoundTrip sends the request and records the response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	query, err := requestQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	line, err := json.Marshal(interaction{
+		Request:  query,
+		Response: recordedResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: string(body)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Close closes the cassette file.
+func (r *Recorder) Close() error {
+	return r.file.Close()
+}
+
+// Replayer is an http.RoundTripper that answers API requests from a recorded
+// cassette without touching the network. Requests are matched on field, term
+// and page. When a request was recorded several times, for example a 429
+// followed by a retry, the responses are replayed in order and the last one
+// is repeated once they run out.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[Query][]recordedResponse
+	played       map[Query]int
+}
+
+// NewReplayer loads the cassette in dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	file, err := os.Open(filepath.Join(dir, CassetteFile))
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	defer file.Close()
+
+	r := &Replayer{
+		interactions: make(map[Query][]recordedResponse),
+		played:       make(map[Query]int),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var i interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		r.interactions[i.Request] = append(r.interactions[i.Request], i.Response)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+
+	return r, nil
+}
+
+// RoundTrip returns the recorded response for the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	query, err := requestQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	responses := r.interactions[query]
+	if len(responses) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("cassette: no recorded response for %s %q page %d", query.Field, query.Value, query.Page)
+	}
+	i := r.played[query]
+	if i < len(responses)-1 {
+		r.played[query]++
+	} else {
+		i = len(responses) - 1
+	}
+	recorded := responses[i]
+	r.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// requestQuery decodes the search query from a request body and restores the body.
+func requestQuery(req *http.Request) (Query, error) {
+	var query Query
+	if req.Body == nil {
+		return query, fmt.Errorf("cassette: request has no body")
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return query, fmt.Errorf("cassette: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := json.Unmarshal(body, &query); err != nil {
+		return query, fmt.Errorf("cassette: %w", err)
+	}
+	return query, nil
+}
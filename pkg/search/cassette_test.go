@@ -0,0 +1,60 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchCassetteTestSuite struct {
+	suite.Suite
+}
+
+// TestRecordReplay tests that a recorded session replays without the network.
+func (s *SearchCassetteTestSuite) TestRecordReplay() {
+	dir := s.T().TempDir()
+	api := &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var query Query
+			s.Require().NoError(json.NewDecoder(req.Body).Decode(&query))
+			body, _ := json.Marshal(Response{
+				Total:        2,
+				Pages:        1,
+				CurrentPage:  query.Page,
+				Certificates: []certificate.Certificate{{Server: query.Value, Names: []string{query.Field.String()}}},
+			})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	recorder, err := NewRecorder(dir, api)
+	s.Require().NoError(err)
+	recorded, err := NewClient(config.Config{}).WithHTTPTransport(recorder).
+		Search(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+	s.Require().NoError(err)
+	s.Require().NoError(recorder.Close())
+	s.Len(recorded, 2)
+
+	replayer, err := NewReplayer(dir)
+	s.Require().NoError(err)
+	client := NewClient(config.Config{}).WithHTTPTransport(replayer).WithRetries(1)
+
+	replayed, err := client.Search(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+	s.Require().NoError(err)
+	s.Equal(recorded, replayed)
+
+	_, err = client.Search(context.Background(), &Query{Field: ByOrg, Value: "example.com"})
+	s.ErrorContains(err, "no recorded response")
+}
+
+// TestRunCassetteTestSuite runs the test suite.
+func TestRunCassetteTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchCassetteTestSuite))
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint records the progress of a paginated search so it can be resumed.
+type Checkpoint struct {
+	// Query is the search being paginated.
+	Query Query `json:"query"`
+	// LastPage is the last page handed to the result channel, and written if
+	// the client waits for write acks, with no gaps before it.
+	LastPage uint64 `json:"last_page"`
+	// Pages is the total number of pages reported by the API.
+	Pages uint64 `json:"total_pages"`
+	// Total is the total number of certificates reported by the API.
+	Total uint64 `json:"total_certificates"`
+	// OutputFile is the file results are written to, if any.
+	OutputFile string `json:"output_file,omitempty"`
+	// UpdatedAt is the time the checkpoint was last saved.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	path string
+}
+
+// NewCheckpoint returns an empty checkpoint that is saved to path.
+func NewCheckpoint(path string) *Checkpoint {
+	return &Checkpoint{path: path}
+}
+
+// LoadCheckpoint reads a checkpoint from path.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: %w", err)
+	}
+
+	cp := &Checkpoint{path: path}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("checkpoint: %s: %w", path, err)
+	}
+
+	return cp, nil
+}
+
+// Done reports whether the checkpointed search reached its last page.
+func (cp *Checkpoint) Done() bool {
+	return cp.LastPage == cp.Pages
+}
+
+// NextQuery returns the query for the page after the last completed one.
+func (cp *Checkpoint) NextQuery() *Query {
+	query := cp.Query
+	query.Page = cp.LastPage + 1
+	return &query
+}
+
+// Save atomically writes the checkpoint to its path.
+func (cp *Checkpoint) Save() error {
+	cp.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+
+	// write to a temporary file first so a crash never leaves a truncated checkpoint.
+	tmp, err := os.CreateTemp(filepath.Dir(cp.path), filepath.Base(cp.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cp.path); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// update records a page that has been handed to the result channel along with
+// every page before it.
+func (cp *Checkpoint) update(query *Query, page uint64, result *Response) error {
+	cp.Query = *query
+	cp.Query.Page = 0
+	cp.LastPage = page
+	cp.Pages = result.Pages
+	cp.Total = result.Total
+	return cp.Save()
+}
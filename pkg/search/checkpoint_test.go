@@ -0,0 +1,122 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchCheckpointTestSuite struct {
+	suite.Suite
+}
+
+// TestCheckpointResume tests that a checkpointed search resumes after the last completed page.
+func (s *SearchCheckpointTestSuite) TestCheckpointResume() {
+	var pages []uint64
+	client := NewClient(config.Config{})
+	client.transport.httpClient.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var query Query
+			s.Require().NoError(json.NewDecoder(req.Body).Decode(&query))
+			pages = append(pages, query.Page)
+			body, _ := json.Marshal(Response{
+				Total:        4,
+				Pages:        3,
+				CurrentPage:  query.Page,
+				Certificates: []certificate.Certificate{{Server: "127.0.0.1:443"}},
+			})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	path := filepath.Join(s.T().TempDir(), "search.checkpoint")
+	client.WithCheckpoint(NewCheckpoint(path)).WithMaxPages(2)
+	s.Require().NoError(stream(client, &Query{Field: ByDomain, Value: "example.com"}))
+
+	cp, err := LoadCheckpoint(path)
+	s.Require().NoError(err)
+	s.Equal(Query{Field: ByDomain, Value: "example.com"}, cp.Query)
+	s.Equal(uint64(1), cp.LastPage)
+	s.Equal(uint64(3), cp.Pages)
+	s.Equal(uint64(4), cp.Total)
+	s.False(cp.Done())
+
+	client.WithCheckpoint(cp).WithMaxPages(0)
+	s.Require().NoError(stream(client, cp.NextQuery()))
+	s.Equal([]uint64{0, 1, 2, 3}, pages)
+
+	cp, err = LoadCheckpoint(path)
+	s.Require().NoError(err)
+	s.True(cp.Done())
+}
+
+// TestCheckpointWriteAcks tests that pages are checkpointed once written, even when interrupted.
+func (s *SearchCheckpointTestSuite) TestCheckpointWriteAcks() {
+	client := NewClient(config.Config{})
+	client.transport.httpClient.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var query Query
+			s.Require().NoError(json.NewDecoder(req.Body).Decode(&query))
+			body, _ := json.Marshal(Response{
+				Total:        4,
+				Pages:        3,
+				CurrentPage:  query.Page,
+				Certificates: []certificate.Certificate{{Server: "127.0.0.1:443"}},
+			})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	path := filepath.Join(s.T().TempDir(), "search.checkpoint")
+	acks := make(chan struct{}, 1)
+	client.WithCheckpoint(NewCheckpoint(path)).WithWriteAcks(acks)
+
+	// the consumer is interrupted while writing the second page, which it
+	// still finishes.
+	ctx, cancel := context.WithCancel(context.Background())
+	resultChan := make(chan []certificate.Certificate)
+	var received int
+	go func() {
+		for range resultChan {
+			if received++; received == 2 {
+				cancel()
+			}
+			acks <- struct{}{}
+		}
+	}()
+	defer close(resultChan)
+
+	err := client.StreamSearchResults(ctx, &Query{Field: ByDomain, Value: "example.com"}, resultChan)
+	s.ErrorIs(err, context.Canceled)
+
+	// the page written after the interrupt is checkpointed, no other page was sent.
+	cp, err := LoadCheckpoint(path)
+	s.Require().NoError(err)
+	s.Equal(uint64(1), cp.LastPage)
+	s.False(cp.Done())
+	s.Equal(2, received)
+}
+
+// stream runs a streaming search, discarding the results.
+func stream(client *Client, query *Query) error {
+	resultChan := make(chan []certificate.Certificate)
+	go func() {
+		for range resultChan {
+		}
+	}()
+	defer close(resultChan)
+	return client.StreamSearchResults(context.Background(), query, resultChan)
+}
+
+// TestRunCheckpointTestSuite runs the test suite.
+func TestRunCheckpointTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchCheckpointTestSuite))
+}
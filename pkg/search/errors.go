@@ -0,0 +1,63 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrBadRequest is returned when the API rejects the search request.
+	ErrBadRequest = errors.New("bad search request")
+	// ErrUnauthorized is returned when the API key is missing or invalid.
+	ErrUnauthorized = errors.New("bad api key")
+	// ErrRateLimited is returned when the API keeps rate limiting requests after all retries.
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrUnexpectedStatus is returned for any other non-200 response.
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// APIError is an unsuccessful response from the certs.io API. It wraps one of
+// the sentinel errors so it can be matched with errors.Is.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body is the response body.
+	Body []byte
+	// Page is the page that was requested.
+	Page uint64
+	// Retries is the number of times the request was retried.
+	Retries int
+	// Err is the sentinel error for the class of failure.
+	Err error
+}
+
+// newAPIError returns an APIError for an unsuccessful status code.
+func newAPIError(statusCode int, body []byte, retries int) *APIError {
+	var err error
+	switch statusCode {
+	case http.StatusBadRequest:
+		err = ErrBadRequest
+	case http.StatusUnauthorized:
+		err = ErrUnauthorized
+	case http.StatusTooManyRequests:
+		err = ErrRateLimited
+	default:
+		err = ErrUnexpectedStatus
+	}
+
+	return &APIError{StatusCode: statusCode, Body: body, Retries: retries, Err: err}
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	if e.Err == ErrUnexpectedStatus {
+		return fmt.Sprintf("%s: %d (page %d, %d retries)", e.Err, e.StatusCode, e.Page, e.Retries)
+	}
+	return fmt.Sprintf("%s (status %d, page %d, %d retries)", e.Err, e.StatusCode, e.Page, e.Retries)
+}
+
+// Unwrap returns the sentinel error.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,516 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/certsio/certsio/pkg/certificate"
+)
+
+// Expr is a node of a parsed boolean search expression.
+type Expr interface {
+	// String returns the canonical form of the expression.
+	String() string
+}
+
+type (
+	// TermExpr matches the certificates returned by a single-field API search.
+	TermExpr struct {
+		Field Field
+		Value string
+	}
+
+	// AndExpr matches certificates matched by both sides.
+	AndExpr struct {
+		Left, Right Expr
+	}
+
+	// OrExpr matches certificates matched by either side.
+	OrExpr struct {
+		Left, Right Expr
+	}
+
+	// NotExpr excludes the certificates matched by Expr.
+	NotExpr struct {
+		Expr Expr
+	}
+)
+
+// String returns the canonical form of the term.
+func (t *TermExpr) String() string {
+	return fmt.Sprintf("%s:%q", t.Field, t.Value)
+}
+
+// String returns the canonical form of the conjunction.
+func (a *AndExpr) String() string {
+	return fmt.Sprintf("(%s AND %s)", a.Left, a.Right)
+}
+
+// String returns the canonical form of the disjunction.
+func (o *OrExpr) String() string {
+	return fmt.Sprintf("(%s OR %s)", o.Left, o.Right)
+}
+
+// String returns the canonical form of the negation.
+func (n *NotExpr) String() string {
+	return fmt.Sprintf("NOT %s", n.Expr)
+}
+
+// ParseExpr parses a boolean search expression such as
+//
+//	org:"Uber" AND NOT ssl_names:*.internal
+//
+// Terms are written as field:value, where value may be double-quoted. Terms
+// can be combined with AND, OR and NOT (case-insensitive) and grouped with
+// parentheses. NOT binds tighter than AND, which binds tighter than OR, and
+// adjacent terms without an operator are joined with AND.
+func ParseExpr(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("search expr: unexpected %s at position %d", tok, tok.pos)
+	}
+
+	// reject expressions that can't be answered without searching every certificate.
+	if _, err := evaluate(expr, func(*TermExpr) (*resultSet, error) { return newResultSet(), nil }); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// Terms returns the distinct single-field queries needed to evaluate expr, in
+// the order they appear.
+func Terms(expr Expr) []*TermExpr {
+	var (
+		terms []*TermExpr
+		seen  = make(map[TermExpr]bool)
+	)
+
+	var walk func(Expr)
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case *TermExpr:
+			if !seen[*e] {
+				seen[*e] = true
+				terms = append(terms, e)
+			}
+		case *AndExpr:
+			walk(e.Left)
+			walk(e.Right)
+		case *OrExpr:
+			walk(e.Left)
+			walk(e.Right)
+		case *NotExpr:
+			walk(e.Expr)
+		}
+	}
+	walk(expr)
+
+	return terms
+}
+
+// SearchExpr runs one API search per distinct term of expr and combines the
+// results client-side. Certificates are identified by their SHA256
+// fingerprint and the server they were seen on.
+func (c *Client) SearchExpr(ctx context.Context, expr Expr) ([]certificate.Certificate, error) {
+	if c.config.checkpoint != nil {
+		return nil, fmt.Errorf("search expr: checkpoints are not supported for expressions")
+	}
+
+	results := make(map[TermExpr]*resultSet)
+	for _, term := range Terms(expr) {
+		set, err := c.searchTerm(ctx, term)
+		if err != nil {
+			return nil, err
+		}
+		results[*term] = set
+	}
+
+	set, err := evaluate(expr, func(t *TermExpr) (*resultSet, error) { return results[*t], nil })
+	if err != nil {
+		return nil, err
+	}
+
+	return set.certificates(), nil
+}
+
+// searchTerm fetches every page of results for a single term.
+func (c *Client) searchTerm(ctx context.Context, term *TermExpr) (*resultSet, error) {
+	pager, err := c.Pages(ctx, &Query{Field: term.Field, Value: term.Value})
+	if err != nil {
+		return nil, fmt.Errorf("search expr: %w", err)
+	}
+	defer pager.Close()
+
+	set := newResultSet()
+	for pager.Next() {
+		for _, cert := range pager.Page().Certificates {
+			set.add(cert)
+		}
+	}
+	if err := pager.Err(); err != nil {
+		return nil, fmt.Errorf("search expr: %w", err)
+	}
+
+	return set, nil
+}
+
+// evaluate combines the result sets of expr's terms. The returned set is the
+// complement of the matches when negated is true, which is only allowed below
+// an AND with a positive side.
+func evaluate(expr Expr, lookup func(*TermExpr) (*resultSet, error)) (*resultSet, error) {
+	set, negated, err := eval(expr, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if negated {
+		return nil, fmt.Errorf("search expr: %s matches an unbounded set, combine NOT with a positive term using AND", expr)
+	}
+	return set, nil
+}
+
+func eval(expr Expr, lookup func(*TermExpr) (*resultSet, error)) (*resultSet, bool, error) {
+	switch e := expr.(type) {
+	case *TermExpr:
+		set, err := lookup(e)
+		return set, false, err
+	case *NotExpr:
+		set, negated, err := eval(e.Expr, lookup)
+		return set, !negated, err
+	case *AndExpr:
+		left, leftNeg, err := eval(e.Left, lookup)
+		if err != nil {
+			return nil, false, err
+		}
+		right, rightNeg, err := eval(e.Right, lookup)
+		if err != nil {
+			return nil, false, err
+		}
+		switch {
+		case !leftNeg && !rightNeg:
+			return left.intersect(right), false, nil
+		case !leftNeg && rightNeg:
+			return left.difference(right), false, nil
+		case leftNeg && !rightNeg:
+			return right.difference(left), false, nil
+		default:
+			// NOT a AND NOT b == NOT (a OR b)
+			return left.union(right), true, nil
+		}
+	case *OrExpr:
+		left, leftNeg, err := eval(e.Left, lookup)
+		if err != nil {
+			return nil, false, err
+		}
+		right, rightNeg, err := eval(e.Right, lookup)
+		if err != nil {
+			return nil, false, err
+		}
+		switch {
+		case !leftNeg && !rightNeg:
+			return left.union(right), false, nil
+		case leftNeg && rightNeg:
+			// NOT a OR NOT b == NOT (a AND b)
+			return left.intersect(right), true, nil
+		default:
+			return nil, false, fmt.Errorf("search expr: %s matches an unbounded set, combine NOT with a positive term using AND", e)
+		}
+	default:
+		return nil, false, fmt.Errorf("search expr: unknown expression %T", expr)
+	}
+}
+
+// resultSet is an insertion-ordered set of certificates.
+type resultSet struct {
+	keys  []string
+	certs map[string]certificate.Certificate
+}
+
+func newResultSet() *resultSet {
+	return &resultSet{certs: make(map[string]certificate.Certificate)}
+}
+
+// certificateKey identifies a certificate seen on a server.
+func certificateKey(cert certificate.Certificate) string {
+	return cert.FingerprintSha256Hash + "|" + cert.Server
+}
+
+func (s *resultSet) add(cert certificate.Certificate) {
+	key := certificateKey(cert)
+	if _, ok := s.certs[key]; ok {
+		return
+	}
+	s.keys = append(s.keys, key)
+	s.certs[key] = cert
+}
+
+func (s *resultSet) has(key string) bool {
+	_, ok := s.certs[key]
+	return ok
+}
+
+func (s *resultSet) union(o *resultSet) *resultSet {
+	out := newResultSet()
+	for _, key := range s.keys {
+		out.add(s.certs[key])
+	}
+	for _, key := range o.keys {
+		out.add(o.certs[key])
+	}
+	return out
+}
+
+func (s *resultSet) intersect(o *resultSet) *resultSet {
+	out := newResultSet()
+	for _, key := range s.keys {
+		if o.has(key) {
+			out.add(s.certs[key])
+		}
+	}
+	return out
+}
+
+func (s *resultSet) difference(o *resultSet) *resultSet {
+	out := newResultSet()
+	for _, key := range s.keys {
+		if !o.has(key) {
+			out.add(s.certs[key])
+		}
+	}
+	return out
+}
+
+func (s *resultSet) certificates() []certificate.Certificate {
+	certs := make([]certificate.Certificate, 0, len(s.keys))
+	for _, key := range s.keys {
+		certs = append(certs, s.certs[key])
+	}
+	return certs
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokTerm
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind  tokenKind
+	pos   int
+	field string
+	value string
+}
+
+// String describes the token for error messages.
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokTerm:
+		return fmt.Sprintf("term %s:%q", t.field, t.value)
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	case tokLParen:
+		return "'('"
+	default:
+		return "')'"
+	}
+}
+
+// lex splits a search expression into tokens.
+func lex(input string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(input)
+		i      int
+	)
+
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !isDelimiter(runes[i]) && runes[i] != ':' {
+				i++
+			}
+			word := string(runes[start:i])
+
+			if i >= len(runes) || runes[i] != ':' {
+				switch strings.ToUpper(word) {
+				case "AND":
+					tokens = append(tokens, token{kind: tokAnd, pos: start})
+				case "OR":
+					tokens = append(tokens, token{kind: tokOr, pos: start})
+				case "NOT":
+					tokens = append(tokens, token{kind: tokNot, pos: start})
+				default:
+					return nil, fmt.Errorf("search expr: expected field:value at position %d, got %q", start, word)
+				}
+				continue
+			}
+
+			// skip the colon and read the value.
+			i++
+			value, next, err := lexValue(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			if value == "" {
+				return nil, fmt.Errorf("search expr: missing value for field %q at position %d", word, start)
+			}
+			i = next
+			tokens = append(tokens, token{kind: tokTerm, pos: start, field: word, value: value})
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+// lexValue reads a quoted or bare value starting at i and returns the value
+// and the position after it.
+func lexValue(runes []rune, i int) (string, int, error) {
+	if i < len(runes) && runes[i] == '"' {
+		var b strings.Builder
+		for j := i + 1; j < len(runes); j++ {
+			switch runes[j] {
+			case '\\':
+				if j+1 < len(runes) {
+					j++
+					b.WriteRune(runes[j])
+				}
+			case '"':
+				return b.String(), j + 1, nil
+			default:
+				b.WriteRune(runes[j])
+			}
+		}
+		return "", 0, fmt.Errorf("search expr: unterminated quote at position %d", i)
+	}
+
+	start := i
+	for i < len(runes) && !isDelimiter(runes[i]) {
+		i++
+	}
+	return string(runes[start:i]), i, nil
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')'
+}
+
+// parser is a recursive descent parser over lexed tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and { OR and }
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &OrExpr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary { [AND] unary }
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokTerm, tokNot, tokLParen:
+			// implicit AND
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &AndExpr{Left: left, Right: right}
+	}
+}
+
+// parseUnary parses: NOT unary | '(' or ')' | term
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNot:
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Expr: expr}, nil
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("search expr: expected ')' at position %d, got %s", closing.pos, closing)
+		}
+		return expr, nil
+	case tokTerm:
+		field, err := ParseField(tok.field)
+		if err != nil {
+			return nil, fmt.Errorf("search expr: position %d: %w", tok.pos, err)
+		}
+		return &TermExpr{Field: field, Value: tok.value}, nil
+	default:
+		return nil, fmt.Errorf("search expr: unexpected %s at position %d", tok, tok.pos)
+	}
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchExprTestSuite struct {
+	suite.Suite
+}
+
+// TestParseExpr tests parsing of boolean search expressions.
+func (s *SearchExprTestSuite) TestParseExpr() {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"term", `domain:example.com`, `domain:"example.com"`},
+		{"quoted", `org:"Uber Technologies, Inc."`, `org:"Uber Technologies, Inc."`},
+		{"colon in value", `server:1.2.3.4:443`, `server:"1.2.3.4:443"`},
+		{"and not", `org:"Uber" AND NOT ssl_names:*.internal`, `(org:"Uber" AND NOT ssl_names:"*.internal")`},
+		{"precedence", `org:a OR org:b and domain:c`, `(org:"a" OR (org:"b" AND domain:"c"))`},
+		{"parens", `(org:a OR org:b) domain:c`, `((org:"a" OR org:"b") AND domain:"c")`},
+	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			expr, err := ParseExpr(tt.input)
+			s.Require().NoError(err)
+			s.Equal(tt.want, expr.String())
+		})
+	}
+}
+
+// TestParseExprErrors tests that invalid expressions are rejected.
+func (s *SearchExprTestSuite) TestParseExprErrors() {
+	for _, input := range []string{
+		``,
+		`example.com`,
+		`unknown:value`,
+		`org:"unterminated`,
+		`(org:a`,
+		`org:a AND`,
+		`NOT org:a`,
+		`org:a OR NOT org:b`,
+	} {
+		s.Run(input, func() {
+			_, err := ParseExpr(input)
+			s.Error(err)
+		})
+	}
+}
+
+// TestSearchExpr tests that term results are combined client-side.
+func (s *SearchExprTestSuite) TestSearchExpr() {
+	certs := map[string][]certificate.Certificate{
+		"org":       {{FingerprintSha256Hash: "a"}, {FingerprintSha256Hash: "b"}, {FingerprintSha256Hash: "c"}},
+		"ssl_names": {{FingerprintSha256Hash: "b"}},
+		"domain":    {{FingerprintSha256Hash: "c"}, {FingerprintSha256Hash: "d"}},
+	}
+
+	var requests int
+	client := NewClient(config.Config{})
+	client.transport.httpClient.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+			var query Query
+			s.Require().NoError(json.NewDecoder(req.Body).Decode(&query))
+			body, _ := json.Marshal(Response{Certificates: certs[query.Field.String()]})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	expr, err := ParseExpr(`org:x AND NOT ssl_names:y OR domain:z`)
+	s.Require().NoError(err)
+
+	results, err := client.SearchExpr(context.Background(), expr)
+	s.Require().NoError(err)
+	s.Equal(3, requests)
+
+	var fingerprints []string
+	for _, cert := range results {
+		fingerprints = append(fingerprints, cert.FingerprintSha256Hash)
+	}
+	s.Equal([]string{"a", "c", "d"}, fingerprints)
+}
+
+// TestRunSearchExprTestSuite runs the test suite.
+func TestRunSearchExprTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchExprTestSuite))
+}
@@ -0,0 +1,241 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Pager iterates over the pages of a search. The first page is fetched by
+// Client.Pages, so Total and Pages are known before iterating:
+//
+//	pager, err := client.Pages(ctx, query)
+//	if err != nil {
+//		return err
+//	}
+//	defer pager.Close()
+//	for pager.Next() {
+//		process(pager.Page().Certificates)
+//	}
+//	return pager.Err()
+//
+// With a concurrency above one, the remaining pages are fetched in the
+// background. Close stops them when iteration ends early.
+type Pager struct {
+	client *Client
+	query  Query
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	first *Response
+	// last is the last page to fetch, capped by the client's maximum number of pages.
+	last uint64
+	// next is the next page to fetch when fetching sequentially.
+	next uint64
+	// results receives pages fetched in the background.
+	results <-chan pageResult
+
+	current *Response
+	// page is the page number of current.
+	page    uint64
+	started bool
+	done    bool
+	err     error
+}
+
+// pageResult is the outcome of fetching a single page.
+type pageResult struct {
+	page     uint64
+	response *Response
+	err      error
+}
+
+// Pages fetches the first page of a search and returns a Pager over all of its pages.
+func (c *Client) Pages(ctx context.Context, query *Query) (*Pager, error) {
+	first, err := c.fetch(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("search %s %q: %w", query.Field, query.Value, err)
+	}
+
+	// check the last page against the maximum number of pages.
+	last := first.Pages
+	if c.config.maxPages > 0 && c.config.maxPages-1 < last {
+		last = c.config.maxPages - 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pager{
+		client: c,
+		query:  *query,
+		ctx:    ctx,
+		cancel: cancel,
+		first:  first,
+		last:   last,
+		next:   first.CurrentPage + 1,
+		page:   first.CurrentPage,
+	}, nil
+}
+
+// Total returns the total number of certificates reported by the API.
+func (p *Pager) Total() uint64 {
+	return p.first.Total
+}
+
+// Pages returns the total number of pages reported by the API.
+func (p *Pager) Pages() uint64 {
+	return p.first.Pages
+}
+
+// Page returns the current page.
+func (p *Pager) Page() *Response {
+	return p.current
+}
+
+// Err returns the error that stopped iteration, if any.
+func (p *Pager) Err() error {
+	return p.err
+}
+
+// Next advances to the next page. It returns false when there are no more
+// pages or an error occurred.
+func (p *Pager) Next() bool {
+	if p.done {
+		return false
+	}
+
+	if !p.started {
+		p.started = true
+		p.current = p.first
+		return true
+	}
+
+	if p.next > p.last {
+		p.finish(nil)
+		return false
+	}
+
+	if p.client.config.concurrency <= 1 {
+		query := p.query
+		query.Page = p.next
+		resp, err := p.client.fetch(p.ctx, &query)
+		if err != nil {
+			p.finish(err)
+			return false
+		}
+		p.current, p.page = resp, p.next
+		p.next++
+		return true
+	}
+
+	if p.results == nil {
+		p.results = p.client.fetchPages(p.ctx, &p.query, p.next, p.last)
+	}
+	result, ok := <-p.results
+	if !ok {
+		p.finish(p.ctx.Err())
+		return false
+	}
+	if result.err != nil {
+		p.finish(result.err)
+		return false
+	}
+	p.current, p.page = result.response, result.page
+	return true
+}
+
+// Close stops fetching pages. It is safe to call Close more than once.
+func (p *Pager) Close() {
+	p.done = true
+	p.cancel()
+}
+
+// finish ends iteration with err.
+func (p *Pager) finish(err error) {
+	if err != nil {
+		p.err = fmt.Errorf("search %s %q: %w", p.query.Field, p.query.Value, err)
+	}
+	p.Close()
+}
+
+// fetchPages fetches pages first through last across the client's workers and
+// returns them on a channel, in page order unless configured otherwise. The
+// channel is closed once every page was sent, an error was sent or ctx is done.
+func (c *Client) fetchPages(ctx context.Context, query *Query, first, last uint64) <-chan pageResult {
+	var (
+		wg      sync.WaitGroup
+		pages   = make(chan uint64)
+		fetched = make(chan pageResult)
+		out     = make(chan pageResult)
+	)
+
+	// queue the pages to fetch
+	go func() {
+		defer close(pages)
+		for page := first; page <= last; page++ {
+			select {
+			case pages <- page:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < c.config.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				pageQuery := *query
+				pageQuery.Page = page
+				resp, err := c.fetch(ctx, &pageQuery)
+				select {
+				case fetched <- pageResult{page: page, response: resp, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(fetched)
+	}()
+
+	// order the fetched pages
+	go func() {
+		defer close(out)
+
+		var (
+			// pending holds pages fetched ahead of the next page to send.
+			pending = make(map[uint64]pageResult)
+			next    = first
+		)
+		for result := range fetched {
+			if result.err != nil || c.config.unordered {
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+				if result.err != nil {
+					return
+				}
+				continue
+			}
+
+			pending[result.page] = result
+			for result, ok := pending[next]; ok; result, ok = pending[next] {
+				delete(pending, next)
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return
+				}
+				next++
+			}
+		}
+	}()
+
+	return out
+}
@@ -1,5 +1,7 @@
 package search
 
+import "fmt"
+
 // Field is a search field.
 type Field string
 
@@ -24,3 +26,16 @@ const (
 func (f Field) String() string {
 	return string(f)
 }
+
+// Fields lists every searchable field.
+var Fields = []Field{ByDomain, ByServer, ByFingerprint, ByEmails, ByOrg, BySerial, ByCertNames}
+
+// ParseField returns the Field matching name.
+func ParseField(name string) (Field, error) {
+	for _, f := range Fields {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown search field %q", name)
+}
@@ -0,0 +1,148 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RapidAPI quota headers.
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitLimit     = "X-RateLimit-Requests-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Requests-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Requests-Reset"
+)
+
+// QuotaState is the request quota reported by the API.
+type QuotaState struct {
+	// Limit is the number of requests allowed in the current period, or -1 if unknown.
+	Limit int64
+	// Remaining is the number of requests left in the current period.
+	Remaining int64
+	// ResetAt is when the quota resets, or the zero time if unknown.
+	ResetAt time.Time
+	// UpdatedAt is when the quota was last reported.
+	UpdatedAt time.Time
+}
+
+// parseQuota reads the quota headers from a response. It returns false if the
+// response carries no quota information.
+func parseQuota(header http.Header, now time.Time) (QuotaState, bool) {
+	remaining, err := strconv.ParseInt(header.Get(headerRateLimitRemaining), 10, 64)
+	if err != nil {
+		return QuotaState{}, false
+	}
+
+	quota := QuotaState{Limit: -1, Remaining: remaining, UpdatedAt: now}
+	if limit, err := strconv.ParseInt(header.Get(headerRateLimitLimit), 10, 64); err == nil {
+		quota.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get(headerRateLimitReset), 10, 64); err == nil {
+		quota.ResetAt = now.Add(time.Duration(reset) * time.Second)
+	}
+
+	return quota, true
+}
+
+// parseRetryAfter returns the delay requested by a Retry-After header, given
+// either in seconds or as an HTTP date.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get(headerRetryAfter)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// rateLimiter is a token bucket that can also be paused until a point in time.
+type rateLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// newRateLimiter returns a limiter allowing rate requests per second with the
+// given burst. A rate of zero disables the token bucket.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	if l.pausedUntil.After(now) {
+		wait = l.pausedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return wait
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens < 0 {
+		if deficit := time.Duration(-l.tokens / l.rate * float64(time.Second)); deficit > wait {
+			wait = deficit
+		}
+	}
+	return wait
+}
+
+// wait blocks until a request may be sent or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	return sleep(ctx, l.reserve(time.Now()))
+}
+
+// pauseUntil holds back all requests until t.
+func (l *rateLimiter) pauseUntil(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -3,12 +3,16 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/certsio/certsio/pkg/config"
 
 	"github.com/cenkalti/backoff"
+	"github.com/sirupsen/logrus"
 
 	"github.com/certsio/certsio/pkg/certificate"
 )
@@ -16,8 +20,13 @@ import (
 const _baseURL = "https://certs-io1.p.rapidapi.com/certificates"
 
 type Config struct {
-	maxPages uint64
-	baseURL  string
+	maxPages    uint64
+	concurrency int
+	unordered   bool
+	baseURL     string
+	checkpoint  *Checkpoint
+	acks        <-chan struct{}
+	cache       *Cache
 }
 
 // Client is the API search client for certs.io
@@ -43,8 +52,9 @@ func NewClient(cfg config.Config) *Client {
 			},
 		}),
 		config: &Config{
-			maxPages: 0,
-			baseURL:  cfg.BaseURL,
+			maxPages:    0,
+			concurrency: 1,
+			baseURL:     cfg.BaseURL,
 		},
 	}
 }
@@ -61,12 +71,71 @@ func (c *Client) WithMaxPages(maxPages uint64) *Client {
 	return c
 }
 
+// WithRateLimit limits requests to rps requests per second with the given burst.
+func (c *Client) WithRateLimit(rps float64, burst int) *Client {
+	c.transport.limiter = newRateLimiter(rps, burst)
+	return c
+}
+
+// WithQuotaCallback sets a function called whenever the API reports the remaining request quota.
+func (c *Client) WithQuotaCallback(fn func(QuotaState)) *Client {
+	c.transport.onQuota = fn
+	return c
+}
+
+// Quota returns the last request quota reported by the API, if any.
+func (c *Client) Quota() (QuotaState, bool) {
+	return c.transport.Quota()
+}
+
+// WithConcurrency sets the number of pages fetched in parallel once the first
+// page has reported the total number of pages.
+func (c *Client) WithConcurrency(workers int) *Client {
+	c.config.concurrency = workers
+	return c
+}
+
+// WithUnorderedResults sends pages to the result channel as they arrive rather
+// than in page order.
+func (c *Client) WithUnorderedResults(unordered bool) *Client {
+	c.config.unordered = unordered
+	return c
+}
+
+// WithCheckpoint saves search progress to cp after every page sent to the result channel.
+func (c *Client) WithCheckpoint(cp *Checkpoint) *Client {
+	c.config.checkpoint = cp
+	return c
+}
+
+// WithWriteAcks makes streaming searches wait for a value on acks for every
+// page sent to the result channel before checkpointing it. The consumer must
+// send one for every page it receives, once the page is written, so that a
+// checkpoint covers exactly the pages in the output.
+func (c *Client) WithWriteAcks(acks <-chan struct{}) *Client {
+	c.config.acks = acks
+	return c
+}
+
+// WithCache answers requests from cache when possible and caches every successful response.
+func (c *Client) WithCache(cache *Cache) *Client {
+	c.config.cache = cache
+	return c
+}
+
 // WithTimeout sets the timeout for API requests.
 func (c *Client) WithTimeout(timeout time.Duration) *Client {
 	c.transport.httpClient.Timeout = timeout
 	return c
 }
 
+// WithHTTPTransport sets the http.RoundTripper used for API requests, such as
+// a cassette Recorder or Replayer.
+func (c *Client) WithHTTPTransport(rt http.RoundTripper) *Client {
+	c.transport.httpClient.Transport = rt
+	return c
+}
+
 // WithRetries sets the maximum number of retries for API requests.
 func (c *Client) WithRetries(retries int) *Client {
 	c.transport.maxRetries = retries
@@ -88,77 +157,129 @@ type Response struct {
 	Certificates []certificate.Certificate `json:"certificates"`
 }
 
-// Search performs a search and wraps the searchWithCtx method.
+// Search performs a search and returns the certificates from every page.
 func (c *Client) Search(ctx context.Context, query *Query) ([]certificate.Certificate, error) {
-	return c.searchWithCtx(ctx, query)
-}
+	pager, err := c.Pages(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer pager.Close()
 
-// searchWithCtx performs a search with a context
-func (c *Client) searchWithCtx(ctx context.Context, query *Query) ([]certificate.Certificate, error) {
 	var results []certificate.Certificate
-	resultChan := make(chan []certificate.Certificate)
-	errChan := make(chan error) // Create a channel to receive errors
-
-	go func() {
-		defer close(resultChan)
-		err := c.search(ctx, query, resultChan)
-		if err != nil {
-			errChan <- err // Send the error to the error channel
-			return
-		}
-	}()
-
-	for certificates := range resultChan {
-		results = append(results, certificates...)
+	for pager.Next() {
+		results = append(results, pager.Page().Certificates...)
 	}
-
-	// Check if there is an error in the error channel
-	select {
-	case err := <-errChan:
-		return nil, err // Return the error
-	default:
-		return results, nil // No error, return the results
+	if err := pager.Err(); err != nil {
+		return nil, err
 	}
+
+	return results, nil
 }
 
 // StreamSearchResults performs a search with a context
 func (c *Client) StreamSearchResults(ctx context.Context, query *Query, resultChan chan<- []certificate.Certificate) error {
-	return c.search(ctx, query, resultChan)
-}
+	pager, err := c.Pages(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer pager.Close()
+
+	var (
+		// completed holds pages sent ahead of the next page to checkpoint.
+		completed = make(map[uint64]*Response)
+		watermark = pager.page
+	)
+	for pager.Next() {
+		// don't hand out more pages once interrupted.
+		if ctx.Err() != nil {
+			return fmt.Errorf("search %s %q: %w", query.Field, query.Value, ctx.Err())
+		}
 
-// search performs a search with a context and streams the certificates to a results channel.
-func (c *Client) search(ctx context.Context, query *Query, resultChan chan<- []certificate.Certificate) error {
-	for {
+		// send the results to the result channel
 		select {
+		case resultChan <- pager.Page().Certificates:
 		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			body, err := json.Marshal(query)
-			if err != nil {
-				return fmt.Errorf("api client: %w", err)
-			}
+			return fmt.Errorf("search %s %q: %w", query.Field, query.Value, ctx.Err())
+		}
+		// the consumer writes every page it received even when interrupted, so
+		// wait for the ack regardless of ctx to checkpoint the page it wrote.
+		if c.config.acks != nil {
+			<-c.config.acks
+		}
 
-			resp, err := c.transport.POST(c.config.baseURL, body)
-			if err != nil {
-				return fmt.Errorf("api client: %w", err)
+		// only checkpoint pages once every page before them has been written.
+		completed[pager.page] = pager.Page()
+		for resp, ok := completed[watermark]; ok; resp, ok = completed[watermark] {
+			delete(completed, watermark)
+			if err := c.saveCheckpoint(query, watermark, resp); err != nil {
+				return err
 			}
+			watermark++
+		}
+	}
+
+	return pager.Err()
+}
 
+// fetch requests a single page of results.
+func (c *Client) fetch(ctx context.Context, query *Query) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// a cache hit skips the API entirely.
+	if c.config.cache != nil {
+		if body, ok := c.config.cache.Get(c.config.baseURL, *query); ok {
 			var result Response
-			// decode the response
-			if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
-				resp.Body.Close()
-				return fmt.Errorf("api client: %w", err)
+			if err := json.Unmarshal(body, &result); err == nil {
+				return &result, nil
 			}
-			resp.Body.Close()
+		}
+	}
 
-			// send the results to the result channel
-			resultChan <- result.Certificates
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("api client: %w", err)
+	}
 
-			// check if we've reached the last page or the maximum number of pages.
-			if result.Pages == result.CurrentPage || (c.config.maxPages > 0 && result.CurrentPage == c.config.maxPages-1) {
-				return nil
-			}
-			query.Page++
+	resp, err := c.transport.POST(ctx, c.config.baseURL, body)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			apiErr.Page = query.Page
 		}
+		return nil, fmt.Errorf("api client: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("api client: %w", err)
+	}
+
+	var result Response
+	// decode the response
+	if err = json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("api client: %w", err)
+	}
+
+	if c.config.cache != nil {
+		// the search succeeded, a cache failure only costs a later request.
+		if err := c.config.cache.Put(c.config.baseURL, *query, respBody); err != nil {
+			logrus.WithError(err).Warn("couldn't cache response")
+		}
+	}
+
+	return &result, nil
+}
+
+// saveCheckpoint records page as completed if checkpointing is enabled.
+func (c *Client) saveCheckpoint(query *Query, page uint64, result *Response) error {
+	if c.config.checkpoint == nil {
+		return nil
+	}
+	if err := c.config.checkpoint.update(query, page, result); err != nil {
+		return fmt.Errorf("api client: %w", err)
 	}
+	return nil
 }
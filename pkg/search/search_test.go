@@ -0,0 +1,208 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchClientTestSuite struct {
+	suite.Suite
+}
+
+// pagedClient returns a client serving totalPages+1 pages, with later pages answered faster.
+func (s *SearchClientTestSuite) pagedClient(totalPages uint64) (*Client, func() []uint64) {
+	var (
+		mu        sync.Mutex
+		requested []uint64
+	)
+	client := NewClient(config.Config{})
+	client.transport.httpClient.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var query Query
+			if err := json.NewDecoder(req.Body).Decode(&query); err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			requested = append(requested, query.Page)
+			mu.Unlock()
+
+			time.Sleep(time.Duration(totalPages-query.Page) * time.Millisecond)
+			body, _ := json.Marshal(Response{
+				Pages:        totalPages,
+				CurrentPage:  query.Page,
+				Certificates: []certificate.Certificate{{Server: fmt.Sprint(query.Page)}},
+			})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	return client, func() []uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		pages := append([]uint64(nil), requested...)
+		sort.Slice(pages, func(i, j int) bool { return pages[i] < pages[j] })
+		return pages
+	}
+}
+
+func servers(certs []certificate.Certificate) []string {
+	var out []string
+	for _, cert := range certs {
+		out = append(out, cert.Server)
+	}
+	return out
+}
+
+// TestConcurrentSearch tests that concurrently fetched pages are delivered in page order.
+func (s *SearchClientTestSuite) TestConcurrentSearch() {
+	s.Run("ordered", func() {
+		client, requested := s.pagedClient(9)
+		client.WithConcurrency(4)
+
+		results, err := client.Search(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+		s.Require().NoError(err)
+		s.Equal([]string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, servers(results))
+		s.Equal([]uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, requested())
+	})
+	s.Run("unordered", func() {
+		client, _ := s.pagedClient(9)
+		client.WithConcurrency(4).WithUnorderedResults(true)
+
+		results, err := client.Search(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+		s.Require().NoError(err)
+		s.Len(results, 10)
+		s.Equal("0", results[0].Server)
+	})
+	s.Run("max pages", func() {
+		client, requested := s.pagedClient(9)
+		client.WithConcurrency(4).WithMaxPages(3)
+
+		results, err := client.Search(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+		s.Require().NoError(err)
+		s.Equal([]string{"0", "1", "2"}, servers(results))
+		s.Equal([]uint64{0, 1, 2}, requested())
+	})
+	s.Run("cancelled", func() {
+		client, _ := s.pagedClient(9)
+		client.WithConcurrency(4)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		resultChan := make(chan []certificate.Certificate)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- client.StreamSearchResults(ctx, &Query{Field: ByDomain, Value: "example.com"}, resultChan)
+		}()
+
+		<-resultChan
+		cancel()
+		s.ErrorIs(<-errChan, context.Canceled)
+	})
+}
+
+// TestPager tests iterating over pages and stopping early.
+func (s *SearchClientTestSuite) TestPager() {
+	s.Run("iterate", func() {
+		client, _ := s.pagedClient(4)
+		pager, err := client.Pages(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+		s.Require().NoError(err)
+		defer pager.Close()
+		s.Equal(uint64(4), pager.Pages())
+
+		var results []certificate.Certificate
+		for pager.Next() {
+			results = append(results, pager.Page().Certificates...)
+		}
+		s.NoError(pager.Err())
+		s.Equal([]string{"0", "1", "2", "3", "4"}, servers(results))
+		s.False(pager.Next())
+	})
+	s.Run("stop early", func() {
+		client, _ := s.pagedClient(99)
+		client.WithConcurrency(8)
+		before := runtime.NumGoroutine()
+
+		pager, err := client.Pages(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+		s.Require().NoError(err)
+		s.True(pager.Next())
+		s.True(pager.Next())
+		pager.Close()
+		s.False(pager.Next())
+		s.NoError(pager.Err())
+
+		// poll without s.Eventually, which runs the condition on its own goroutines.
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		s.LessOrEqual(runtime.NumGoroutine(), before)
+	})
+	s.Run("error", func() {
+		client := NewClient(config.Config{})
+		client.transport.httpClient.Transport = &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusBadRequest}, nil
+			},
+		}
+		_, err := client.Search(context.Background(), &Query{Field: ByDomain, Value: "example.com"})
+		s.ErrorIs(err, ErrBadRequest)
+	})
+}
+
+// TestStreamBatch tests that batch results are tagged with their term and failures are reported per term.
+func (s *SearchClientTestSuite) TestStreamBatch() {
+	client := NewClient(config.Config{})
+	client.transport.httpClient.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var query Query
+			if err := json.NewDecoder(req.Body).Decode(&query); err != nil {
+				return nil, err
+			}
+			if query.Value == "bad.example" {
+				return &http.Response{StatusCode: http.StatusBadRequest}, nil
+			}
+			body, _ := json.Marshal(Response{Total: 1, Certificates: []certificate.Certificate{{Server: query.Value}}})
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		},
+	}
+
+	resultChan := make(chan []certificate.Certificate)
+	tags := make(map[string]string)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for certs := range resultChan {
+			for _, cert := range certs {
+				tags[cert.Server] = cert.QueryTerm
+			}
+		}
+	}()
+
+	results := client.StreamBatch(context.Background(), ByDomain, []string{"a.example", "bad.example", "b.example"}, 2, resultChan)
+	close(resultChan)
+	<-done
+
+	s.Equal(map[string]string{"a.example": "a.example", "b.example": "b.example"}, tags)
+	s.Require().Len(results, 3)
+	s.Equal(TermResult{Term: "a.example", Total: 1, Received: 1}, results[0])
+	s.Equal("bad.example", results[1].Term)
+	s.ErrorIs(results[1].Err, ErrBadRequest)
+	s.NoError(results[2].Err)
+}
+
+// TestRunSearchClientTestSuite runs the test suite.
+func TestRunSearchClientTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchClientTestSuite))
+}
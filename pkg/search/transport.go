@@ -2,8 +2,10 @@ package search
 
 import (
 	"bytes"
-	"fmt"
+	"context"
+	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -17,6 +19,12 @@ type TransportConfig struct {
 	RetryBackoff  func(attempt int) time.Duration
 	MaxRetries    int
 	ApiKey        string
+	// RequestsPerSecond limits the request rate, zero disables the limit.
+	RequestsPerSecond float64
+	// Burst is the number of requests that may be sent at once under the rate limit.
+	Burst int
+	// OnQuota is called whenever the API reports the remaining request quota.
+	OnQuota func(QuotaState)
 }
 
 // Transport is the HTTP transport for the certs.io API.
@@ -27,6 +35,11 @@ type Transport struct {
 	apiKey       string
 	maxRetries   int
 	retryBackoff func(attempt int) time.Duration
+
+	limiter *rateLimiter
+	onQuota func(QuotaState)
+	quotaMu sync.Mutex
+	quota   *QuotaState
 }
 
 // NewTransport returns a new HTTP transport.
@@ -49,6 +62,8 @@ func NewTransport(cfg TransportConfig) *Transport {
 		retryBackoff: cfg.RetryBackoff,
 		userAgent:    "certsio-client-go",
 		apiKey:       cfg.ApiKey,
+		limiter:      newRateLimiter(cfg.RequestsPerSecond, cfg.Burst),
+		onQuota:      cfg.OnQuota,
 	}
 
 	// set custom transport if provided
@@ -59,51 +74,102 @@ func NewTransport(cfg TransportConfig) *Transport {
 	return client
 }
 
-// POST performs a POST request to the certs.io API with retries.
-func (t *Transport) POST(url string, body []byte) (*http.Response, error) {
+// POST performs a POST request to the certs.io API with retries. Cancelling
+// ctx aborts the request in flight and any wait between retries.
+func (t *Transport) POST(ctx context.Context, url string, body []byte) (*http.Response, error) {
 	var (
 		resp *http.Response
 		err  error
 	)
 	for i := 0; i < t.maxRetries; i++ {
-		resp, err = t.post(url, body)
+		// wait for the rate limiter, which is also paused by the quota headers.
+		if err = t.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err = t.post(ctx, url, body)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
 			continue
 		}
+		t.observeQuota(resp)
 
 		// if the status code is 200, break out of the loop.
 		if resp.StatusCode == http.StatusOK {
 			break
 		}
 
-		if resp.StatusCode == http.StatusBadRequest {
-			err = fmt.Errorf("bad search request")
-			break
-		}
-
-		if resp.StatusCode == http.StatusUnauthorized {
-			err = fmt.Errorf("bad api key")
+		err = t.statusError(resp, i)
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
 			break
 		}
 		// if the status code is 429, retry with backoff.
 		if resp.StatusCode == http.StatusTooManyRequests {
-			err = fmt.Errorf("rate limit exceeded")
-		} else {
-			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			// honor the delay requested by the API instead of backing off blindly.
+			if delay, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+				t.limiter.pauseUntil(time.Now().Add(delay))
+				continue
+			}
 		}
 
 		// Delay the retry if a backoff function is configured
 		if t.retryBackoff != nil {
-			time.Sleep(t.retryBackoff(i + 1))
+			if err := sleep(ctx, t.retryBackoff(i+1)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return resp, err
 }
 
+// statusError returns an *APIError for an unsuccessful response. The body is
+// read into the error and replaced so the response can still be inspected.
+func (t *Transport) statusError(resp *http.Response, retries int) error {
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return newAPIError(resp.StatusCode, body, retries)
+}
+
+// Quota returns the last request quota reported by the API, if any.
+func (t *Transport) Quota() (QuotaState, bool) {
+	t.quotaMu.Lock()
+	defer t.quotaMu.Unlock()
+	if t.quota == nil {
+		return QuotaState{}, false
+	}
+	return *t.quota, true
+}
+
+// observeQuota records the quota reported in a response and pauses requests
+// until the quota resets once it has been used up.
+func (t *Transport) observeQuota(resp *http.Response) {
+	quota, ok := parseQuota(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+
+	t.quotaMu.Lock()
+	t.quota = &quota
+	t.quotaMu.Unlock()
+
+	if quota.Remaining <= 0 && !quota.ResetAt.IsZero() {
+		t.limiter.pauseUntil(quota.ResetAt)
+	}
+	if t.onQuota != nil {
+		t.onQuota(quota)
+	}
+}
+
 // Post performs a POST request to the certs.io API.
-func (t *Transport) post(url string, body []byte) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+func (t *Transport) post(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
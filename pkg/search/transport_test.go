@@ -1,10 +1,16 @@
 package search
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/certsio/certsio/pkg/certificate"
+	"github.com/certsio/certsio/pkg/config"
+
 	"github.com/stretchr/testify/suite"
 )
 
@@ -56,7 +62,7 @@ func (s *SearchTransportTestSuite) TestTransportPOST() {
 			},
 		}
 		t := NewTransport(config)
-		resp, err := t.POST("http://localhost", []byte("body"))
+		resp, err := t.POST(context.Background(), "http://localhost", []byte("body"))
 		s.Nil(err)
 		s.Equal(http.StatusOK, resp.StatusCode)
 		s.Equal(numReqs, i)
@@ -80,7 +86,7 @@ func (s *SearchTransportTestSuite) TestTransportPOST() {
 			MaxRetries: 5,
 		}
 		t := NewTransport(config)
-		resp, err := t.POST("http://localhost", []byte("body"))
+		resp, err := t.POST(context.Background(), "http://localhost", []byte("body"))
 		s.Nil(err)
 		s.Equal(http.StatusOK, resp.StatusCode)
 		s.Equal(numReqs, i)
@@ -100,7 +106,7 @@ func (s *SearchTransportTestSuite) TestTransportPOST() {
 			},
 		}
 		t := NewTransport(config)
-		resp, err := t.POST("http://localhost", []byte("body"))
+		resp, err := t.POST(context.Background(), "http://localhost", []byte("body"))
 		s.NotNil(err)
 		s.Equal(http.StatusBadRequest, resp.StatusCode)
 		s.Equal(numReqs, i)
@@ -125,7 +131,7 @@ func (s *SearchTransportTestSuite) TestTransportPOST() {
 			MaxRetries: maxRetries,
 		}
 		t := NewTransport(config)
-		_, err := t.POST("http://localhost", []byte("body"))
+		_, err := t.POST(context.Background(), "http://localhost", []byte("body"))
 		s.NotNil(err)
 		s.Equal(maxRetries, i)
 	})
@@ -142,11 +148,161 @@ func (s *SearchTransportTestSuite) TestTransportPOST() {
 			},
 		}
 		t := NewTransport(config)
-		_, err := t.POST("http://localhost", []byte("body"))
+		_, err := t.POST(context.Background(), "http://localhost", []byte("body"))
 		s.NotNil(err)
 	})
 }
 
+// TestTransportContext tests that cancelling the context aborts requests and backoff waits.
+func (s *SearchTransportTestSuite) TestTransportContext() {
+	s.Run("request", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		config := TransportConfig{
+			HTTPTransport: &mockTransport{
+				RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+					cancel()
+					<-req.Context().Done()
+					return nil, req.Context().Err()
+				},
+			},
+		}
+		t := NewTransport(config)
+		_, err := t.POST(ctx, "http://localhost", []byte("body"))
+		s.ErrorIs(err, context.Canceled)
+	})
+	s.Run("backoff", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		config := TransportConfig{
+			HTTPTransport: &mockTransport{
+				RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+					cancel()
+					return &http.Response{StatusCode: http.StatusTooManyRequests}, nil
+				},
+			},
+			RetryBackoff: func(int) time.Duration { return time.Hour },
+		}
+		t := NewTransport(config)
+		_, err := t.POST(ctx, "http://localhost", []byte("body"))
+		s.ErrorIs(err, context.Canceled)
+	})
+}
+
+// TestTransportErrors tests that unsuccessful responses return typed errors.
+func (s *SearchTransportTestSuite) TestTransportErrors() {
+	tests := []struct {
+		status  int
+		want    error
+		retries int
+	}{
+		{http.StatusBadRequest, ErrBadRequest, 0},
+		{http.StatusUnauthorized, ErrUnauthorized, 0},
+		{http.StatusTooManyRequests, ErrRateLimited, 2},
+		{http.StatusInternalServerError, ErrUnexpectedStatus, 2},
+	}
+	for _, tt := range tests {
+		s.Run(http.StatusText(tt.status), func() {
+			config := TransportConfig{
+				HTTPTransport: &mockTransport{
+					RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+						return &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader("details"))}, nil
+					},
+				},
+			}
+			t := NewTransport(config)
+			_, err := t.POST(context.Background(), "http://localhost", []byte("body"))
+			s.ErrorIs(err, tt.want)
+
+			var apiErr *APIError
+			s.Require().ErrorAs(err, &apiErr)
+			s.Equal(tt.status, apiErr.StatusCode)
+			s.Equal([]byte("details"), apiErr.Body)
+			s.Equal(tt.retries, apiErr.Retries)
+		})
+	}
+
+	s.Run("page", func() {
+		client := NewClient(config.Config{})
+		client.transport.httpClient.Transport = &mockTransport{
+			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusUnauthorized}, nil
+			},
+		}
+		err := client.StreamSearchResults(context.Background(), &Query{Field: ByDomain, Value: "example.com", Page: 7}, make(chan []certificate.Certificate))
+
+		var apiErr *APIError
+		s.Require().ErrorAs(err, &apiErr)
+		s.ErrorIs(err, ErrUnauthorized)
+		s.Equal(uint64(7), apiErr.Page)
+	})
+}
+
+// TestTransportRateLimit tests the rate limiter and the RapidAPI quota headers.
+func (s *SearchTransportTestSuite) TestTransportRateLimit() {
+	s.Run("token bucket", func() {
+		now := time.Now()
+		l := newRateLimiter(2, 2)
+		l.last = now
+		s.Zero(l.reserve(now))
+		s.Zero(l.reserve(now))
+		s.Equal(500*time.Millisecond, l.reserve(now))
+		s.Equal(500*time.Millisecond, l.reserve(now.Add(500*time.Millisecond)))
+	})
+	s.Run("Retry-After", func() {
+		var (
+			i       int
+			backoff bool
+		)
+		config := TransportConfig{
+			HTTPTransport: &mockTransport{
+				RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+					i++
+					if i == 2 {
+						return &http.Response{StatusCode: http.StatusOK}, nil
+					}
+					return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"0"}}}, nil
+				},
+			},
+			RetryBackoff: func(int) time.Duration {
+				backoff = true
+				return 0
+			},
+		}
+		t := NewTransport(config)
+		resp, err := t.POST(context.Background(), "http://localhost", []byte("body"))
+		s.Nil(err)
+		s.Equal(http.StatusOK, resp.StatusCode)
+		s.False(backoff)
+	})
+	s.Run("quota", func() {
+		var reported []QuotaState
+		config := TransportConfig{
+			HTTPTransport: &mockTransport{
+				RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Header: http.Header{
+						"X-Ratelimit-Requests-Limit":     {"100"},
+						"X-Ratelimit-Requests-Remaining": {"0"},
+						"X-Ratelimit-Requests-Reset":     {"60"},
+					}}, nil
+				},
+			},
+			OnQuota: func(q QuotaState) { reported = append(reported, q) },
+		}
+		t := NewTransport(config)
+		_, err := t.POST(context.Background(), "http://localhost", []byte("body"))
+		s.Nil(err)
+		s.Require().Len(reported, 1)
+		s.Equal(int64(100), reported[0].Limit)
+		s.Equal(int64(0), reported[0].Remaining)
+
+		quota, ok := t.Quota()
+		s.True(ok)
+		s.Equal(reported[0], quota)
+
+		// the quota is used up, so the next request waits for the reset.
+		s.InDelta(time.Minute, t.limiter.reserve(time.Now()), float64(time.Second))
+	})
+}
+
 // TestRunTransportTestSuite runs the test suite.
 func TestRunTransportTestSuite(t *testing.T) {
 	suite.Run(t, new(SearchTransportTestSuite))